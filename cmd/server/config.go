@@ -0,0 +1,420 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/JayJamieson/csv-api/pkg/api"
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"go.yaml.in/yaml/v3"
+)
+
+const configUsage = `Usage: server config [flags] print
+
+Prints the configuration merged from the config file, flags and environment
+variables, then reports invalid settings.
+
+Flags:
+`
+
+// setting is a server option read from the config file, a flag and an
+// environment variable, each overriding the one before.
+type setting struct {
+	// key is the dotted path of the setting in the config file.
+	key  string
+	flag string
+	env  string
+	// list settings are comma separated in flags and environment variables.
+	list bool
+	// mask hides secrets when the setting is printed.
+	mask func(string) string
+}
+
+var settings = []setting{
+	{key: "port", flag: "port", env: "PORT"},
+	{key: "database_url", flag: "db-url", env: "DATABASE_URL", mask: maskAuthToken},
+	{key: "data_dir", flag: "data-dir", env: "DATA_DIR"},
+	{key: "log.level", flag: "log-level", env: "LOG_LEVEL"},
+	{key: "metrics.enabled", flag: "metrics", env: "METRICS"},
+	{key: "tracing.otlp_endpoint", flag: "otlp-endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT"},
+	{key: "tracing.sample_ratio", flag: "trace-sample-ratio", env: "TRACE_SAMPLE_RATIO"},
+	{key: "auth.require_api_key", flag: "require-api-key", env: "REQUIRE_API_KEY"},
+	{key: "auth.jwks", flag: "jwks", env: "JWKS_URL"},
+	{key: "auth.jwt_issuer", flag: "jwt-issuer", env: "JWT_ISSUER"},
+	{key: "auth.jwt_audience", flag: "jwt-audience", env: "JWT_AUDIENCE"},
+	{key: "auth.jwt_owner_claim", flag: "jwt-owner-claim", env: "JWT_OWNER_CLAIM"},
+	{key: "share.secret", flag: "share-secret", env: "SHARE_SECRET", mask: maskSecret},
+	{key: "share.ttl", flag: "share-ttl", env: "SHARE_TTL"},
+	{key: "share.max_ttl", flag: "share-max-ttl", env: "SHARE_MAX_TTL"},
+	{key: "limits.read_rate", flag: "read-rate", env: "READ_RATE_LIMIT"},
+	{key: "limits.read_burst", flag: "read-burst", env: "READ_BURST"},
+	{key: "limits.import_rate", flag: "import-rate", env: "IMPORT_RATE_LIMIT"},
+	{key: "limits.import_burst", flag: "import-burst", env: "IMPORT_BURST"},
+	{key: "limits.max_storage_bytes", flag: "max-storage-bytes", env: "MAX_STORAGE_BYTES"},
+	{key: "limits.max_datasets", flag: "max-datasets", env: "MAX_DATASETS"},
+	{key: "limits.max_upload_bytes", flag: "max-upload-bytes", env: "MAX_UPLOAD_BYTES"},
+	{key: "limits.max_body_bytes", flag: "max-body-bytes", env: "MAX_BODY_BYTES"},
+	{key: "server.cors_origins", flag: "cors-origins", env: "CORS_ORIGINS", list: true},
+	{key: "server.trusted_proxies", flag: "trusted-proxies", env: "TRUSTED_PROXIES", list: true},
+	{key: "server.tls_cert", flag: "tls-cert", env: "TLS_CERT_FILE"},
+	{key: "server.tls_key", flag: "tls-key", env: "TLS_KEY_FILE"},
+	{key: "server.read_timeout", flag: "read-timeout", env: "READ_TIMEOUT"},
+	{key: "server.write_timeout", flag: "write-timeout", env: "WRITE_TIMEOUT"},
+	{key: "server.idle_timeout", flag: "idle-timeout", env: "IDLE_TIMEOUT"},
+	{key: "server.shutdown_timeout", flag: "shutdown-timeout", env: "SHUTDOWN_TIMEOUT"},
+}
+
+// serverFlags holds the options of the server.
+type serverFlags struct {
+	fs *flag.FlagSet
+
+	configFile      *string
+	port            *int
+	dbURL           *string
+	dataDir         *string
+	logLevel        *string
+	metrics         *bool
+	otlpEndpoint    *string
+	sampleRatio     *float64
+	requireAPIKey   *bool
+	jwks            *string
+	jwtIssuer       *string
+	jwtAudience     *string
+	jwtOwnerClaim   *string
+	shareSecret     *string
+	shareTTL        *time.Duration
+	shareMaxTTL     *time.Duration
+	readRate        *float64
+	readBurst       *int
+	importRate      *float64
+	importBurst     *int
+	maxStorage      *int64
+	maxDatasets     *int
+	maxUpload       *int64
+	maxBody         *int64
+	corsOrigins     *string
+	trustedProxies  *string
+	tlsCert         *string
+	tlsKey          *string
+	readTimeout     *time.Duration
+	writeTimeout    *time.Duration
+	idleTimeout     *time.Duration
+	shutdownTimeout *time.Duration
+}
+
+func newServerFlags(fs *flag.FlagSet) *serverFlags {
+	f := &serverFlags{
+		fs:              fs,
+		configFile:      fs.String("config", "", "YAML or TOML config file, overridden by flags and environment variables ($CONFIG_FILE)"),
+		port:            fs.Int("port", 8001, "Server port"),
+		dbURL:           fs.String("db-url", "file:data.db", "Turso database URL"),
+		dataDir:         fs.String("data-dir", db.DefaultDataDir, "Directory of the DuckDB files of datasets"),
+		logLevel:        fs.String("log-level", "info", "Log level: debug, info, warn, error or off"),
+		metrics:         fs.Bool("metrics", false, "Serve Prometheus metrics at /metrics without authentication"),
+		otlpEndpoint:    fs.String("otlp-endpoint", "", "URL of an OpenTelemetry collector receiving traces over OTLP/HTTP"),
+		sampleRatio:     fs.Float64("trace-sample-ratio", 1, "Share of traces exported, between 0 and 1"),
+		requireAPIKey:   fs.Bool("require-api-key", false, "Require an API key on every request"),
+		jwks:            fs.String("jwks", "", "File or URL of the JWKS verifying JWT bearer tokens"),
+		jwtIssuer:       fs.String("jwt-issuer", "", "Required issuer of JWT bearer tokens"),
+		jwtAudience:     fs.String("jwt-audience", "", "Required audience of JWT bearer tokens"),
+		jwtOwnerClaim:   fs.String("jwt-owner-claim", "sub", "JWT claim identifying the owner of datasets"),
+		shareSecret:     fs.String("share-secret", "", "Secret signing share links, random when empty"),
+		shareTTL:        fs.Duration("share-ttl", 24*time.Hour, "Time share links are valid unless the request says otherwise"),
+		shareMaxTTL:     fs.Duration("share-max-ttl", 30*24*time.Hour, "Longest time share links can be valid"),
+		readRate:        fs.Float64("read-rate", 0, "Read requests per second allowed per client, 0 for no limit"),
+		readBurst:       fs.Int("read-burst", 0, "Read requests a client can make at once, defaults to the rate"),
+		importRate:      fs.Float64("import-rate", 0, "Import and write requests per second allowed per client, 0 for no limit"),
+		importBurst:     fs.Int("import-burst", 0, "Import and write requests a client can make at once, defaults to the rate"),
+		maxStorage:      fs.Int64("max-storage-bytes", 0, "DuckDB storage allowed per owner in bytes, 0 for no limit"),
+		maxDatasets:     fs.Int("max-datasets", 0, "Datasets allowed per owner, 0 for no limit"),
+		maxUpload:       fs.Int64("max-upload-bytes", 0, "Size limit of uploaded CSV files, 0 for no limit"),
+		maxBody:         fs.Int64("max-body-bytes", 10<<20, "Size limit of other request bodies, 0 for no limit"),
+		corsOrigins:     fs.String("cors-origins", "", "Comma separated origins allowed to call the API from a browser, any when empty"),
+		trustedProxies:  fs.String("trusted-proxies", "", "Comma separated IPs or CIDR ranges of proxies trusted for X-Forwarded headers"),
+		tlsCert:         fs.String("tls-cert", "", "TLS certificate file, reloaded on SIGHUP"),
+		tlsKey:          fs.String("tls-key", "", "TLS private key file, reloaded on SIGHUP"),
+		readTimeout:     fs.Duration("read-timeout", 0, "Time allowed to read a request including its body, 0 for no limit"),
+		writeTimeout:    fs.Duration("write-timeout", 0, "Time allowed to handle a request and write its response, 0 for no limit"),
+		idleTimeout:     fs.Duration("idle-timeout", 2*time.Minute, "Time an idle keep-alive connection is kept open"),
+		shutdownTimeout: fs.Duration("shutdown-timeout", 10*time.Second, "Time to wait for requests in flight on shutdown"),
+	}
+
+	for _, s := range settings {
+		fs.Lookup(s.flag).Usage += fmt.Sprintf(" ($%s)", s.env)
+	}
+
+	return f
+}
+
+// parse parses the flags in args, then applies the config file to the
+// settings without a flag and the environment variables over both.
+func (f *serverFlags) parse(args []string) error {
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *f.configFile == "" {
+		*f.configFile = os.Getenv("CONFIG_FILE")
+	}
+	if *f.configFile != "" {
+		if err := f.applyConfigFile(*f.configFile); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if err := f.fs.Set(s.flag, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s environment variable %q: %w", s.env, value, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyConfigFile sets the flags not given on the command line to the
+// settings of a YAML or TOML file.
+func (f *serverFlags) applyConfigFile(path string) error {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	flat := make(map[string]string)
+	if err := flattenConfig("", values, flat); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	given := make(map[string]bool)
+	f.fs.Visit(func(fl *flag.Flag) {
+		given[fl.Name] = true
+	})
+
+	var errs []error
+	for _, s := range settings {
+		value, ok := flat[s.key]
+		if !ok {
+			continue
+		}
+		delete(flat, s.key)
+
+		if given[s.flag] {
+			continue
+		}
+		if err := f.fs.Set(s.flag, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid %s %q: %w", path, s.key, value, err))
+		}
+	}
+
+	unknown := make([]string, 0, len(flat))
+	for key := range flat {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("%s: unknown setting %s", path, key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// readConfigFile decodes a config file by its extension.
+func readConfigFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// flattenConfig adds the settings of nested tables to flat by their dotted
+// keys, joining lists with commas as flags expect.
+func flattenConfig(prefix string, values map[string]any, flat map[string]string) error {
+	for key, value := range values {
+		key = prefix + key
+
+		switch value := value.(type) {
+		case nil:
+		case map[string]any:
+			if err := flattenConfig(key+".", value, flat); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				switch item.(type) {
+				case map[string]any, []any:
+					return fmt.Errorf("%s must be a list of values", key)
+				}
+				items[i] = fmt.Sprint(item)
+			}
+			flat[key] = strings.Join(items, ",")
+		default:
+			flat[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+// config returns the server configuration of the flags.
+func (f *serverFlags) config() api.Config {
+	return api.Config{
+		Port:             *f.port,
+		DatabaseURL:      *f.dbURL,
+		DataDir:          *f.dataDir,
+		LogLevel:         *f.logLevel,
+		Metrics:          *f.metrics,
+		OTLPEndpoint:     *f.otlpEndpoint,
+		TraceSampleRatio: *f.sampleRatio,
+		RequireAPIKey:    *f.requireAPIKey,
+		ShareSecret:      *f.shareSecret,
+		ShareTTL:         *f.shareTTL,
+		ShareMaxTTL:      *f.shareMaxTTL,
+		JWKS:             *f.jwks,
+		JWTIssuer:        *f.jwtIssuer,
+		JWTAudience:      *f.jwtAudience,
+		JWTOwnerClaim:    *f.jwtOwnerClaim,
+		ReadRateLimit:    api.RateLimit{Rate: *f.readRate, Burst: *f.readBurst},
+		ImportRateLimit:  api.RateLimit{Rate: *f.importRate, Burst: *f.importBurst},
+		Quota:            db.Quota{MaxBytes: *f.maxStorage, MaxDatasets: *f.maxDatasets},
+		AllowedOrigins:   splitList(*f.corsOrigins),
+		TLSCertFile:      *f.tlsCert,
+		TLSKeyFile:       *f.tlsKey,
+		ReadTimeout:      *f.readTimeout,
+		WriteTimeout:     *f.writeTimeout,
+		IdleTimeout:      *f.idleTimeout,
+		MaxUploadBytes:   *f.maxUpload,
+		MaxBodyBytes:     *f.maxBody,
+		TrustedProxies:   splitList(*f.trustedProxies),
+		ShutdownTimeout:  *f.shutdownTimeout,
+	}
+}
+
+// effective returns the settings in effect nested as in a config file, with
+// secrets masked.
+func (f *serverFlags) effective() map[string]any {
+	values := make(map[string]any)
+	for _, s := range settings {
+		var value any = f.fs.Lookup(s.flag).Value.(flag.Getter).Get()
+		switch v := value.(type) {
+		case string:
+			if s.mask != nil {
+				value = s.mask(v)
+			}
+			if s.list {
+				value = append([]string{}, splitList(v)...)
+			}
+		case time.Duration:
+			value = v.String()
+		}
+
+		table := values
+		path := strings.Split(s.key, ".")
+		for _, name := range path[:len(path)-1] {
+			if _, ok := table[name]; !ok {
+				table[name] = make(map[string]any)
+			}
+			table = table[name].(map[string]any)
+		}
+		table[path[len(path)-1]] = value
+	}
+	return values
+}
+
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "REDACTED"
+}
+
+// maskAuthToken masks the auth token and password of a database URL.
+func maskAuthToken(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return maskSecret(value)
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	query := u.Query()
+	if query.Has("authToken") {
+		query.Set("authToken", "REDACTED")
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+func runConfig(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	flags := newServerFlags(fs)
+	format := fs.String("format", "yaml", "Output format: yaml or toml")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), configUsage)
+		fs.PrintDefaults()
+	}
+
+	// Accept flags after the command as well as before it.
+	command := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	if err := flags.parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if command == "" {
+		command = fs.Arg(0)
+	}
+	if command != "print" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch *format {
+	case "yaml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		err = encoder.Encode(flags.effective())
+	case "toml":
+		err = toml.NewEncoder(os.Stdout).Encode(flags.effective())
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := flags.config().Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
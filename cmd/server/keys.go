@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+)
+
+const keysUsage = `Usage: server keys [flags] <command>
+
+Commands:
+  create   Mint an API key for -owner and print it
+  revoke   Revoke the API key with the given ID
+  list     Print all API keys
+
+Flags:
+`
+
+func runKeys(args []string) {
+	fs := flag.NewFlagSet("keys", flag.ExitOnError)
+	flags := newServerFlags(fs)
+	owner := fs.String("owner", "", "Owner of the datasets created with the key")
+	name := fs.String("name", "", "Description of the key")
+	admin := fs.Bool("admin", false, "Allow the key to access datasets of every owner")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), keysUsage)
+		fs.PrintDefaults()
+	}
+	if err := flags.parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	command := fs.Arg(0)
+	if command == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	database, err := db.Open(*flags.dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+
+	if _, err := database.Migrate(ctx); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	switch command {
+	case "create":
+		apiKey, key, err := database.CreateAPIKey(ctx, *owner, *name, *admin)
+		if err != nil {
+			log.Fatalf("Failed to create API key: %v", err)
+		}
+		fmt.Printf("id:  %s\nkey: %s\n", apiKey.ID, key)
+		fmt.Fprintln(os.Stderr, "Store the key now, it cannot be shown again.")
+	case "revoke":
+		id := fs.Arg(1)
+		if id == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if err := database.RevokeAPIKey(ctx, id); err != nil {
+			log.Fatalf("Failed to revoke API key: %v", err)
+		}
+		fmt.Printf("revoked %s\n", id)
+	case "list":
+		keys, err := database.ListAPIKeys(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list API keys: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tOWNER\tNAME\tADMIN\tCREATED\tLAST USED\tREVOKED")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n", k.ID, k.Owner, k.Name, k.Admin,
+				k.CreatedAt.Format(time.RFC3339), formatTime(k.LastUsedAt), formatTime(k.RevokedAt))
+		}
+		w.Flush()
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
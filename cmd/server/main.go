@@ -2,41 +2,65 @@ package main
 
 import (
 	"flag"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/JayJamieson/csv-api/pkg/api"
+	"github.com/JayJamieson/csv-api/pkg/logging"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
 
-	port := flag.Int("port", 8001, "Server port")
-	dbURL := flag.String("db-url", "file:data.db", "Turso database URL")
-	flag.Parse()
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		runKeys(os.Args[2:])
+		return
+	}
 
-	if envPort := os.Getenv("PORT"); envPort != "" {
-		if p, err := fmt.Sscanf(envPort, "%d", port); err != nil || p != 1 {
-			log.Printf("Invalid PORT environment variable: %s, using default: %d", envPort, *port)
-		}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfig(os.Args[2:])
+		return
 	}
 
-	if envDBURL := os.Getenv("DATABASE_URL"); envDBURL != "" {
-		*dbURL = envDBURL
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		runToken(os.Args[2:])
+		return
 	}
 
-	config := api.Config{
-		Port:        *port,
-		DatabaseURL: *dbURL,
+	slog.SetDefault(logging.New(os.Stderr, new(slog.LevelVar)))
+
+	flags := newServerFlags(flag.CommandLine)
+	if err := flags.parse(os.Args[1:]); err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
 	}
 
+	config := flags.config()
+
 	server, err := api.New(config)
 
 	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+		slog.Error("Failed to create server", "error", err)
+		os.Exit(1)
 	}
 
 	if err := server.Start(); err != nil {
-		log.Fatalf("Server error: %v", err)
+		slog.Error("Server error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+)
+
+const migrateUsage = `Usage: server migrate [flags] <command>
+
+Commands:
+  up       Apply all pending migrations
+  status   Print pending migrations without applying them
+
+Flags:
+`
+
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags := newServerFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+	if err := flags.parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	command := fs.Arg(0)
+	if command == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	database, err := db.Open(*flags.dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := database.Migrate(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "status":
+		pending, err := database.PendingMigrations(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migrations: %v", err)
+		}
+		for _, m := range pending {
+			fmt.Printf("pending %04d_%s\n", m.Version, m.Name)
+		}
+		if len(pending) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
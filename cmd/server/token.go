@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/auth"
+)
+
+const tokenUsage = `Usage: server token [flags]
+
+Sign a JWT with a local private key, to test JWT authentication against a
+JWKS holding its public key such as testdata/jwks/jwks.json. With -jwks the
+JWKS of the key is printed instead.
+
+Flags:
+`
+
+func runToken(args []string) {
+	fs := flag.NewFlagSet("token", flag.ExitOnError)
+	keyFile := fs.String("key", "testdata/jwks/rs256.pem", "PEM encoded RSA or P-256 private key")
+	kid := fs.String("kid", "test-rs256", "Key ID of the signing key in the JWKS")
+	issuer := fs.String("iss", "csv-api-test", "Issuer claim")
+	audience := fs.String("aud", "csv-api", "Audience claim")
+	subject := fs.String("sub", "", "Subject claim, the owner of datasets")
+	scope := fs.String("scope", "read import persist", "Space separated scopes")
+	ttl := fs.Duration("ttl", time.Hour, "Time until the token expires")
+	printJWKS := fs.Bool("jwks", false, "Print the JWKS of the key instead of a token")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), tokenUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	key, err := loadSigningKey(*keyFile)
+	if err != nil {
+		log.Fatalf("Failed to load key: %v", err)
+	}
+
+	if *printJWKS {
+		jwk, err := auth.PublicJWK(key, *kid)
+		if err != nil {
+			log.Fatalf("Failed to encode key: %v", err)
+		}
+		out, _ := json.MarshalIndent(map[string]any{"keys": []any{jwk}}, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if *subject == "" {
+		log.Fatal("-sub is required")
+	}
+
+	now := time.Now()
+	token, err := auth.Sign(key, *kid, map[string]any{
+		"iss":   *issuer,
+		"aud":   *audience,
+		"sub":   *subject,
+		"scope": strings.Join(strings.Fields(*scope), " "),
+		"iat":   now.Unix(),
+		"exp":   now.Add(*ttl).Unix(),
+	})
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+
+	fmt.Println(token)
+}
+
+func loadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return auth.ParsePrivateKey(data)
+}
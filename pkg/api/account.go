@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAccount implements ServerInterface.
+func (h *Server) GetAccount(ctx echo.Context) error {
+	reqCtx := ctx.Request().Context()
+
+	usage, err := h.db.Usage(reqCtx)
+	if err != nil {
+		return dbErrorResponse(ctx, "Account error", err)
+	}
+
+	resp := AccountResponse{
+		Ok: true,
+		Usage: Usage{
+			Datasets: usage.Datasets,
+			Bytes:    usage.Bytes,
+		},
+	}
+
+	if p := db.PrincipalFromContext(reqCtx); p != nil {
+		resp.Owner = p.Owner
+		resp.Admin = p.Admin
+	}
+
+	if !resp.Admin {
+		quota := h.db.Quota()
+		resp.Quota = Quota{MaxDatasets: quota.MaxDatasets, MaxBytes: quota.MaxBytes}
+	}
+
+	if h.readLimiter != nil {
+		resp.RateLimits.Read = h.readLimiter.limit()
+	}
+	if h.importLimiter != nil {
+		resp.RateLimits.Import = h.importLimiter.limit()
+	}
+
+	return ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+// AggregateCSV implements ServerInterface.
+func (h *Server) AggregateCSV(ctx echo.Context, id types.UUID, params AggregateCSVParams) error {
+	result, err := h.db.Aggregate(ctx.Request().Context(), &db.AggregateQuery{
+		ID:      id.String(),
+		GroupBy: params.GroupBy,
+		Agg:     params.Agg,
+		Filters: params.Filter,
+		Limit:   params.Limit,
+		Format:  string(params.Format),
+	})
+	if err != nil {
+		return dbErrorResponse(ctx, "Aggregate error", err)
+	}
+
+	ctx.Set(rowsKey, result.Total)
+
+	return ctx.JSON(http.StatusOK, CSVResponse{
+		Ok:      true,
+		Total:   result.Total,
+		QueryMs: result.QueryMs,
+		Columns: result.Columns,
+		Rows:    result.Rows,
+	})
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/JayJamieson/csv-api/pkg/auth"
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// Scopes gating the operations of the API, named by the x-scope extension of
+// each operation in the spec. The admin scope grants every operation on every
+// dataset.
+const (
+	scopeRead    = "read"
+	scopeImport  = "import"
+	scopePersist = "persist"
+	scopeAdmin   = "admin"
+)
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// operation is an operation of the embedded spec.
+type operation struct {
+	id    string
+	scope string
+}
+
+// loadOperations maps the method and Echo route of each operation in the
+// embedded spec to its ID and x-scope.
+func loadOperations() (map[string]operation, error) {
+	swagger, err := GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load swagger: %w", err)
+	}
+
+	operations := make(map[string]operation)
+	for path, item := range swagger.Paths.Map() {
+		route := pathParam.ReplaceAllString(path, ":$1")
+		for method, op := range item.Operations() {
+			scope, _ := op.Extensions["x-scope"].(string)
+			switch scope {
+			case scopeRead, scopeImport, scopePersist, scopeAdmin:
+			default:
+				return nil, fmt.Errorf("operation %s has invalid x-scope %q", op.OperationID, scope)
+			}
+			operations[method+" "+route] = operation{id: op.OperationID, scope: scope}
+		}
+	}
+
+	return operations, nil
+}
+
+// operation returns the operation handling c, if any.
+func (s *Server) operation(c echo.Context) (operation, bool) {
+	op, ok := s.operations[c.Request().Method+" "+c.Path()]
+	return op, ok
+}
+
+// withoutAdminRoutes registers the operations of the API except those needing
+// the admin scope, which anyone could use when requests are not
+// authenticated.
+type withoutAdminRoutes struct {
+	*echo.Echo
+	operations map[string]operation
+}
+
+func (r withoutAdminRoutes) add(method, path string, h echo.HandlerFunc, m []echo.MiddlewareFunc) *echo.Route {
+	if r.operations[method+" "+path].scope == scopeAdmin {
+		return nil
+	}
+	return r.Echo.Add(method, path, h, m...)
+}
+
+func (r withoutAdminRoutes) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodConnect, path, h, m)
+}
+
+func (r withoutAdminRoutes) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodDelete, path, h, m)
+}
+
+func (r withoutAdminRoutes) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodGet, path, h, m)
+}
+
+func (r withoutAdminRoutes) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodHead, path, h, m)
+}
+
+func (r withoutAdminRoutes) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodOptions, path, h, m)
+}
+
+func (r withoutAdminRoutes) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodPatch, path, h, m)
+}
+
+func (r withoutAdminRoutes) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodPost, path, h, m)
+}
+
+func (r withoutAdminRoutes) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodPut, path, h, m)
+}
+
+func (r withoutAdminRoutes) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodTrace, path, h, m)
+}
+
+// keyScopes are the scopes of API keys, which can do anything with the
+// datasets of their owner.
+func keyScopes(p *db.Principal) []string {
+	if p.Admin {
+		return []string{scopeAdmin}
+	}
+	return []string{scopeRead, scopeImport, scopePersist}
+}
+
+// authenticate authenticates requests with an API key, sent as a bearer token
+// or in the X-API-Key header, or with a JWT bearer token when an identity
+// provider is configured. It scopes the datasets they can use to the owner of
+// the key or token and checks the scope of the operation. Reads of a single
+// dataset are allowed without a key, for public datasets or with a share
+// link. The API documentation and metrics stay public.
+func (s *Server) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		path := c.Request().URL.Path
+		if path == "/doc.yml" || path == "/metrics" || strings.HasPrefix(path, "/swagger/") {
+			return next(c)
+		}
+
+		key := c.Request().Header.Get("X-API-Key")
+		if header := c.Request().Header.Get(echo.HeaderAuthorization); key == "" && header != "" {
+			scheme, token, ok := strings.Cut(header, " ")
+			if ok && strings.EqualFold(scheme, "Bearer") {
+				key = strings.TrimSpace(token)
+			}
+		}
+
+		id := c.Param("id")
+		read := c.Request().Method == http.MethodGet || c.Request().Method == http.MethodHead
+
+		principal := &db.Principal{}
+		scopes := []string{scopeRead}
+		switch {
+		case key == "":
+			if id == "" || !read {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return errorResponse(c, http.StatusUnauthorized, "Unauthorized", "API key required")
+			}
+
+		case s.jwt != nil && isJWT(key):
+			claims, err := s.jwt.Validate(c.Request().Context(), key)
+			if err != nil {
+				if errors.Is(err, auth.ErrInvalidToken) {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+					return errorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+				}
+				return errorResponse(c, http.StatusInternalServerError, "Authentication error", err.Error())
+			}
+
+			principal, scopes, err = s.tokenPrincipal(claims)
+			if err != nil {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				return errorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+			}
+
+		default:
+			var err error
+			principal, err = s.db.Authenticate(c.Request().Context(), key)
+			if err != nil {
+				if errors.Is(err, db.ErrUnauthorized) {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+					return errorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+				}
+				return errorResponse(c, http.StatusInternalServerError, "Authentication error", err.Error())
+			}
+			scopes = keyScopes(principal)
+		}
+
+		// Routes that are not API operations respond with 404 below.
+		if op, ok := s.operation(c); ok && !hasScope(scopes, op.scope) {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate,
+				fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, op.scope))
+			return errorResponse(c, http.StatusForbidden, "Forbidden", fmt.Sprintf("%s scope required", op.scope))
+		}
+
+		// Share links only grant reads, writes still need the owner's key.
+		if token := c.QueryParam("share"); token != "" && read {
+			if id == "" || !s.verifyShare(id, token) {
+				return errorResponse(c, http.StatusUnauthorized, "Unauthorized", "invalid or expired share link")
+			}
+			principal.SharedID = id
+		}
+
+		c.SetRequest(c.Request().WithContext(db.WithPrincipal(c.Request().Context(), principal)))
+		return next(c)
+	}
+}
+
+// isJWT tells tokens apart from API keys, which never contain dots.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// tokenPrincipal maps the claims of a JWT to the principal owning datasets
+// and its scopes.
+func (s *Server) tokenPrincipal(claims auth.Claims) (*db.Principal, []string, error) {
+	ownerClaim := s.config.JWTOwnerClaim
+	if ownerClaim == "" {
+		ownerClaim = "sub"
+	}
+
+	owner := claims.String(ownerClaim)
+	if owner == "" {
+		return nil, nil, fmt.Errorf("token has no %s claim", ownerClaim)
+	}
+
+	scopes := claims.Scopes()
+	return &db.Principal{Owner: owner, Admin: slices.Contains(scopes, scopeAdmin)}, scopes, nil
+}
+
+func hasScope(scopes []string, scope string) bool {
+	return slices.Contains(scopes, scope) || slices.Contains(scopes, scopeAdmin)
+}
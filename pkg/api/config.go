@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/logging"
+)
+
+// Validate reports every invalid setting of config.
+func (config Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if config.Port < 1 || config.Port > 65535 {
+		invalid("port must be between 1 and 65535, got %d", config.Port)
+	}
+	if config.DatabaseURL == "" {
+		invalid("database URL is required")
+	}
+	if _, err := logging.ParseLevel(config.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.TraceSampleRatio < 0 || config.TraceSampleRatio > 1 {
+		invalid("trace sample ratio must be between 0 and 1, got %g", config.TraceSampleRatio)
+	}
+
+	if config.JWKS != "" && (config.JWTIssuer == "" || config.JWTAudience == "") {
+		invalid("JWT issuer and audience are required with a JWKS")
+	}
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		invalid("both a TLS certificate and key are required")
+	}
+	if _, err := parseTrustedProxies(config.TrustedProxies); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.ReadRateLimit.Rate < 0 || config.ReadRateLimit.Burst < 0 {
+		invalid("read rate limit must not be negative")
+	}
+	if config.ImportRateLimit.Rate < 0 || config.ImportRateLimit.Burst < 0 {
+		invalid("import rate limit must not be negative")
+	}
+	if config.Quota.MaxBytes < 0 || config.Quota.MaxDatasets < 0 {
+		invalid("quota must not be negative")
+	}
+	if config.MaxUploadBytes < 0 || config.MaxBodyBytes < 0 {
+		invalid("body size limits must not be negative")
+	}
+
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read timeout", config.ReadTimeout},
+		{"write timeout", config.WriteTimeout},
+		{"idle timeout", config.IdleTimeout},
+		{"shutdown timeout", config.ShutdownTimeout},
+		{"share TTL", config.ShareTTL},
+		{"share max TTL", config.ShareMaxTTL},
+	} {
+		if d.value < 0 {
+			invalid("%s must not be negative, got %s", d.name, d.value)
+		}
+	}
+	if config.shareTTL() > config.shareMaxTTL() {
+		invalid("share TTL %s exceeds the share max TTL %s", config.shareTTL(), config.shareMaxTTL())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// shareTTL returns how long share links are valid by default.
+func (config Config) shareTTL() time.Duration {
+	if config.ShareTTL > 0 {
+		return config.ShareTTL
+	}
+	return defaultShareTTL
+}
+
+// shareMaxTTL returns how long share links can be valid at most.
+func (config Config) shareMaxTTL() time.Duration {
+	if config.ShareMaxTTL > 0 {
+		return config.ShareMaxTTL
+	}
+	return defaultShareMaxTTL
+}
@@ -20,6 +20,36 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+const (
+	ApiKeyHeaderScopes = "apiKeyHeader.Scopes"
+	BearerAuthScopes   = "bearerAuth.Scopes"
+	ShareLinkScopes    = "shareLink.Scopes"
+)
+
+// Defines values for LogLevel.
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelError LogLevel = "error"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelOff   LogLevel = "off"
+	LogLevelWarn  LogLevel = "warn"
+)
+
+// Defines values for ViewDatasetJoin.
+const (
+	Full  ViewDatasetJoin = "full"
+	Inner ViewDatasetJoin = "inner"
+	Left  ViewDatasetJoin = "left"
+	Right ViewDatasetJoin = "right"
+)
+
+// Defines values for Visibility.
+const (
+	Private  Visibility = "private"
+	Public   Visibility = "public"
+	Unlisted Visibility = "unlisted"
+)
+
 // Defines values for FetchCSVParamsSortOrder.
 const (
 	ASC  FetchCSVParamsSortOrder = "ASC"
@@ -28,17 +58,185 @@ const (
 
 // Defines values for FetchCSVParamsFormat.
 const (
-	Array   FetchCSVParamsFormat = "array"
-	Objects FetchCSVParamsFormat = "objects"
+	FetchCSVParamsFormatArray   FetchCSVParamsFormat = "array"
+	FetchCSVParamsFormatObjects FetchCSVParamsFormat = "objects"
+)
+
+// Defines values for FetchCSVParamsRowid.
+const (
+	Hide FetchCSVParamsRowid = "hide"
+	Show FetchCSVParamsRowid = "show"
+)
+
+// Defines values for FetchCSVParamsLabels.
+const (
+	Name     FetchCSVParamsLabels = "name"
+	Original FetchCSVParamsLabels = "original"
+)
+
+// Defines values for AggregateCSVParamsFormat.
+const (
+	AggregateCSVParamsFormatArray   AggregateCSVParamsFormat = "array"
+	AggregateCSVParamsFormatObjects AggregateCSVParamsFormat = "objects"
+)
+
+// Defines values for AppendCSVParamsMissing.
+const (
+	AppendCSVParamsMissingError AppendCSVParamsMissing = "error"
+	AppendCSVParamsMissingNull  AppendCSVParamsMissing = "null"
+)
+
+// Defines values for AppendCSVParamsExtra.
+const (
+	Add    AppendCSVParamsExtra = "add"
+	Error  AppendCSVParamsExtra = "error"
+	Ignore AppendCSVParamsExtra = "ignore"
+)
+
+// Defines values for AppendCSVParamsMode.
+const (
+	Append AppendCSVParamsMode = "append"
+	Merge  AppendCSVParamsMode = "merge"
+)
+
+// Defines values for GetRowParamsFormat.
+const (
+	Array   GetRowParamsFormat = "array"
+	Objects GetRowParamsFormat = "objects"
 )
 
+// AccountResponse defines model for AccountResponse.
+type AccountResponse struct {
+	Admin bool `json:"admin"`
+	Ok    bool `json:"ok"`
+
+	// Owner Owner of the caller's datasets, empty without authentication
+	Owner string `json:"owner"`
+
+	// Quota Storage limits, omitted when unlimited. Admins are not limited.
+	Quota Quota `json:"quota"`
+
+	// RateLimits Limits of each client, omitted when unlimited
+	RateLimits RateLimits `json:"rate_limits"`
+	Usage      Usage      `json:"usage"`
+}
+
+// AlterSchemaRequest defines model for AlterSchemaRequest.
+type AlterSchemaRequest struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+// AppendResponse defines model for AppendResponse.
+type AppendResponse struct {
+	Deleted  int    `json:"deleted"`
+	Endpoint string `json:"endpoint"`
+	Inserted int    `json:"inserted"`
+	Ok       bool   `json:"ok"`
+	Updated  int    `json:"updated"`
+}
+
 // CSVResponse defines model for CSVResponse.
 type CSVResponse struct {
-	Columns []string      `json:"columns,omitempty"`
+	Columns []string `json:"columns,omitempty"`
+
+	// Facets Value counts of the requested facets over the filtered rows
+	Facets []Facet `json:"facets,omitempty"`
+
+	// Next Cursor for the next page, omitted on the last page
+	Next    string        `json:"next,omitempty"`
 	Ok      bool          `json:"ok,omitempty"`
 	QueryMs float64       `json:"query_ms,omitempty"`
 	Rows    []interface{} `json:"rows,omitempty"`
-	Total   int           `json:"total,omitempty"`
+
+	// Snippets For searches, the matching words of each row's search columns keyed
+	// by column name, in the same order as `rows`. Matches are wrapped in
+	// `<mark>` tags and the text is HTML escaped.
+	Snippets []map[string]string `json:"snippets,omitempty"`
+
+	// SuggestedFacets Columns with few distinct values that make useful facets
+	SuggestedFacets []string `json:"suggested_facets,omitempty"`
+	Total           int      `json:"total,omitempty"`
+}
+
+// CastErrorResponse defines model for CastErrorResponse.
+type CastErrorResponse struct {
+	Error     string        `json:"error"`
+	Failures  []CastFailure `json:"failures"`
+	Message   string        `json:"message"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// CastFailure defines model for CastFailure.
+type CastFailure struct {
+	Column string `json:"column"`
+
+	// Count Number of values that cannot be cast
+	Count int64 `json:"count"`
+
+	// Rows The first failing rows
+	Rows []struct {
+		Rowid int64       `json:"rowid"`
+		Value interface{} `json:"value"`
+	} `json:"rows"`
+	Type string `json:"type"`
+}
+
+// ColumnProfile defines model for ColumnProfile.
+type ColumnProfile struct {
+	DistinctCount int64 `json:"distinct_count"`
+
+	// Max Largest value
+	Max interface{} `json:"max"`
+
+	// Mean Mean of numeric columns
+	Mean *float64 `json:"mean,omitempty"`
+
+	// Min Smallest value
+	Min          interface{}   `json:"min"`
+	Name         string        `json:"name"`
+	NullCount    int64         `json:"null_count"`
+	SampleValues []interface{} `json:"sample_values"`
+
+	// Stddev Sample standard deviation of numeric columns
+	Stddev    *float64     `json:"stddev,omitempty"`
+	TopValues []FacetValue `json:"top_values"`
+	Type      string       `json:"type"`
+}
+
+// Dataset defines model for Dataset.
+type Dataset struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Endpoint  string             `json:"endpoint"`
+	Filename  string             `json:"filename"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// Owner Owner of the API key that created the dataset
+	Owner     string `json:"owner,omitempty"`
+	Persisted bool   `json:"persisted"`
+
+	// SuggestedFacets Columns with few distinct values that make useful facets
+	SuggestedFacets []string `json:"suggested_facets,omitempty"`
+	Version         int64    `json:"version"`
+
+	// View The dataset is a view joining other datasets
+	View bool `json:"view"`
+
+	// Visibility Who can read a dataset besides its owner and admins: nobody, holders
+	// of a share link, or anyone
+	Visibility Visibility `json:"visibility"`
+	Writable   bool       `json:"writable"`
+}
+
+// DatasetListResponse defines model for DatasetListResponse.
+type DatasetListResponse struct {
+	Datasets []Dataset `json:"datasets"`
+	Ok       bool      `json:"ok"`
+}
+
+// DatasetResponse defines model for DatasetResponse.
+type DatasetResponse struct {
+	Dataset Dataset `json:"dataset"`
+	Ok      bool    `json:"ok"`
 }
 
 // ErrorResponse defines model for ErrorResponse.
@@ -48,12 +246,186 @@ type ErrorResponse struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// Facet defines model for Facet.
+type Facet struct {
+	Column string `json:"column"`
+
+	// Truncated The column has more values than were returned
+	Truncated bool         `json:"truncated"`
+	Values    []FacetValue `json:"values"`
+}
+
+// FacetValue defines model for FacetValue.
+type FacetValue struct {
+	Count int64 `json:"count"`
+
+	// Value Column value, null for rows without a value
+	Value interface{} `json:"value"`
+}
+
 // ImportResponse defines model for ImportResponse.
 type ImportResponse struct {
 	Endpoint string `json:"endpoint"`
 	Ok       bool   `json:"ok"`
 }
 
+// InsertRowsRequest Rows as objects keyed by column name or arrays of values in column order
+type InsertRowsRequest = []interface{}
+
+// JoinKeys defines model for JoinKeys.
+type JoinKeys struct {
+	// Left Column of an earlier dataset, as `column` or `alias.column`
+	Left string `json:"left"`
+
+	// Right Column of the joined dataset
+	Right string `json:"right"`
+}
+
+// LogLevel defines model for LogLevel.
+type LogLevel string
+
+// LogLevelRequest defines model for LogLevelRequest.
+type LogLevelRequest struct {
+	Level LogLevel `json:"level"`
+}
+
+// LogLevelResponse defines model for LogLevelResponse.
+type LogLevelResponse struct {
+	Level LogLevel `json:"level"`
+	Ok    bool     `json:"ok"`
+}
+
+// ProfileResponse defines model for ProfileResponse.
+type ProfileResponse struct {
+	// Cached The profile was computed by an earlier request for the same dataset version
+	Cached      bool            `json:"cached"`
+	Columns     []ColumnProfile `json:"columns"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Ok          bool            `json:"ok"`
+	RowCount    int64           `json:"row_count"`
+
+	// Version Dataset version the profile was computed for
+	Version int64 `json:"version"`
+}
+
+// Quota Storage limits, omitted when unlimited. Admins are not limited.
+type Quota struct {
+	MaxBytes    int64 `json:"max_bytes,omitempty"`
+	MaxDatasets int   `json:"max_datasets,omitempty"`
+}
+
+// RateLimit defines model for RateLimit.
+type RateLimit struct {
+	// Burst Requests allowed at once
+	Burst int `json:"burst"`
+
+	// Rate Requests per second
+	Rate float64 `json:"rate"`
+}
+
+// RateLimits Limits of each client, omitted when unlimited
+type RateLimits struct {
+	Import *RateLimit `json:"import,omitempty"`
+	Read   *RateLimit `json:"read,omitempty"`
+}
+
+// RowsResponse defines model for RowsResponse.
+type RowsResponse struct {
+	Affected int  `json:"affected"`
+	Ok       bool `json:"ok"`
+}
+
+// SchemaChange defines model for SchemaChange.
+type SchemaChange struct {
+	// Column Current column name
+	Column string `json:"column"`
+
+	// Rename New column name
+	Rename string `json:"rename,omitempty"`
+
+	// Type Type to cast the column to: BOOLEAN, TINYINT, SMALLINT, INTEGER,
+	// BIGINT, HUGEINT, UTINYINT, USMALLINT, UINTEGER, UBIGINT, FLOAT,
+	// REAL, DOUBLE, DECIMAL(p,s), VARCHAR, DATE, TIME, TIMESTAMP,
+	// TIMESTAMPTZ or UUID
+	Type string `json:"type,omitempty"`
+}
+
+// SchemaColumn defines model for SchemaColumn.
+type SchemaColumn struct {
+	// Key The column is the dataset's unique key
+	Key bool `json:"key"`
+
+	// Label Header of the column in the imported file, set for datasets imported with `normalize_headers`
+	Label    string `json:"label,omitempty"`
+	Name     string `json:"name"`
+	Nullable bool   `json:"nullable"`
+	Type     string `json:"type"`
+}
+
+// SchemaResponse defines model for SchemaResponse.
+type SchemaResponse struct {
+	Columns []SchemaColumn `json:"columns"`
+	Ok      bool           `json:"ok"`
+}
+
+// ShareResponse defines model for ShareResponse.
+type ShareResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Ok        bool      `json:"ok"`
+	Url       string    `json:"url"`
+}
+
+// UpdateRowRequest Column values to set, keyed by column name
+type UpdateRowRequest map[string]interface{}
+
+// Usage defines model for Usage.
+type Usage struct {
+	// Bytes Total size of the DuckDB files of the datasets
+	Bytes int64 `json:"bytes"`
+
+	// Datasets Datasets and views
+	Datasets int `json:"datasets"`
+}
+
+// ViewDataset defines model for ViewDataset.
+type ViewDataset struct {
+	// Alias Name used to refer to the dataset in join keys and column prefixes, defaults to its file name
+	Alias string             `json:"alias,omitempty"`
+	Id    openapi_types.UUID `json:"id"`
+
+	// Join How the dataset is joined, defaults to inner
+	Join ViewDatasetJoin `json:"join,omitempty"`
+	On   []JoinKeys      `json:"on,omitempty"`
+}
+
+// ViewDatasetJoin How the dataset is joined, defaults to inner
+type ViewDatasetJoin string
+
+// ViewRequest defines model for ViewRequest.
+type ViewRequest struct {
+	// Datasets Datasets to join. Each dataset after the first is joined to the
+	// ones before it. Columns named like a column of an earlier dataset
+	// are prefixed with the dataset alias and an underscore.
+	Datasets []ViewDataset `json:"datasets"`
+
+	// Materialized Copy the joined rows instead of joining on every query
+	Materialized bool `json:"materialized,omitempty"`
+
+	// Name Name of the view, used as its file name
+	Name string `json:"name,omitempty"`
+}
+
+// Visibility Who can read a dataset besides its owner and admins: nobody, holders
+// of a share link, or anyone
+type Visibility string
+
+// VisibilityRequest defines model for VisibilityRequest.
+type VisibilityRequest struct {
+	// Visibility Who can read a dataset besides its owner and admins: nobody, holders
+	// of a share link, or anyone
+	Visibility Visibility `json:"visibility"`
+}
+
 // FetchCSVParams defines parameters for FetchCSV.
 type FetchCSVParams struct {
 	// Limit Limit the number of rows returned
@@ -65,11 +437,55 @@ type FetchCSVParams struct {
 	// SortOrder Sort order for sortColumn
 	SortOrder FetchCSVParamsSortOrder `form:"sortOrder,omitempty" json:"sortOrder,omitempty"`
 
-	// Offset Offset for pagination
+	// Sort Comma separated sort keys, applied in order. Prefix a column with `-`
+	// to sort descending and suffix it with `:nulls_first` or `:nulls_last`
+	// to place null values, which sort last by default. Cannot be combined
+	// with `sortColumn`.
+	Sort string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Offset Offset for pagination, cannot be combined with `cursor`
 	Offset int `form:"offset,omitempty" json:"offset,omitempty"`
 
+	// Cursor Opaque cursor from the `next` field of a previous response. Returns
+	// the rows after the last row of that page using the same sort.
+	Cursor string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
 	// Format Output JSON format `objects` for array of objects, `array` for array of arrays
 	Format FetchCSVParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Rowid Show or hide the `_rowid` column holding the stable row identifier
+	Rowid FetchCSVParamsRowid `form:"rowid,omitempty" json:"rowid,omitempty"`
+
+	// Labels Name the returned columns by column name, or by the header as
+	// written in the imported file for datasets imported with
+	// `normalize_headers`
+	Labels FetchCSVParamsLabels `form:"labels,omitempty" json:"labels,omitempty"`
+
+	// Columns Comma separated columns to return, in order. Items can also be
+	// computed columns written as `expression as alias`, using column
+	// names, number and string literals, `+ - * / %`, `||`, parentheses
+	// and the functions abs, coalesce, length, lower, ltrim, nullif,
+	// replace, round, rtrim, substr, trim and upper.
+	Columns string `form:"columns,omitempty" json:"columns,omitempty"`
+
+	// Exclude Comma separated columns to leave out of the result
+	Exclude string `form:"exclude,omitempty" json:"exclude,omitempty"`
+
+	// Filter Row filters written as `column:op:value`, all of which must match.
+	// Operators are eq, ne, gt, gte, lt, lte, contains, startswith,
+	// endswith, in and notin (values separated by `|`), isnull and notnull.
+	Filter []string `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Q Full-text search over the dataset's search columns. Matching rows
+	// are ranked by relevance, returned in the `_score` column, unless
+	// sorted otherwise and the response includes highlighted snippets.
+	Q string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Facet Columns to count the most common values of over the filtered rows
+	Facet []string `form:"facet,omitempty" json:"facet,omitempty"`
+
+	// FacetSize Number of values returned per facet
+	FacetSize int `form:"facet_size,omitempty" json:"facet_size,omitempty"`
 }
 
 // FetchCSVParamsSortOrder defines parameters for FetchCSV.
@@ -78,6 +494,95 @@ type FetchCSVParamsSortOrder string
 // FetchCSVParamsFormat defines parameters for FetchCSV.
 type FetchCSVParamsFormat string
 
+// FetchCSVParamsRowid defines parameters for FetchCSV.
+type FetchCSVParamsRowid string
+
+// FetchCSVParamsLabels defines parameters for FetchCSV.
+type FetchCSVParamsLabels string
+
+// AggregateCSVParams defines parameters for AggregateCSV.
+type AggregateCSVParams struct {
+	// GroupBy Comma separated columns to group by, omit to aggregate all rows
+	GroupBy string `form:"group_by,omitempty" json:"group_by,omitempty"`
+
+	// Agg Comma separated aggregates written as `function:column`. Functions
+	// are count, count_distinct, sum, avg, min and max, `count:*` counts rows.
+	Agg string `form:"agg" json:"agg"`
+
+	// Filter Row filters written as `column:op:value`, all of which must match.
+	// Operators are eq, ne, gt, gte, lt, lte, contains, startswith,
+	// endswith, in and notin (values separated by `|`), isnull and notnull.
+	Filter []string `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Limit Limit the number of groups returned
+	Limit int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Format Output JSON format `objects` for array of objects, `array` for array of arrays
+	Format AggregateCSVParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// AggregateCSVParamsFormat defines parameters for AggregateCSV.
+type AggregateCSVParamsFormat string
+
+// AppendCSVParams defines parameters for AppendCSV.
+type AppendCSVParams struct {
+	// Url HTTP URL of the CSV file to append
+	Url string `form:"url,omitempty" json:"url,omitempty"`
+
+	// Name Name of the CSV file when uploading directly
+	Name string `form:"name,omitempty" json:"name,omitempty"`
+
+	// Missing Handling of dataset columns missing from the CSV, `null` fills them with nulls, `error` rejects the file
+	Missing AppendCSVParamsMissing `form:"missing,omitempty" json:"missing,omitempty"`
+
+	// Extra Handling of CSV columns not in the dataset, `error` rejects the file, `ignore` drops them and `add` adds them to the dataset
+	Extra AppendCSVParamsExtra `form:"extra,omitempty" json:"extra,omitempty"`
+
+	// Strict Keep dataset column types as they are. By default column types are widened
+	// (e.g. BIGINT to DOUBLE or VARCHAR) when appended values do not fit, in strict
+	// mode values are cast to the existing types and values that cannot be cast
+	// fail the append.
+	Strict bool `form:"strict,omitempty" json:"strict,omitempty"`
+
+	// Mode `append` adds all rows of the CSV. `merge` matches rows on the `key` columns,
+	// updating existing rows with a matching key and inserting the others. Every
+	// row needs a value for the key columns, CSV files with null keys are rejected.
+	// Re-imports of a corrected file are merged into the existing dataset here,
+	// `/import` always creates a new dataset.
+	Mode AppendCSVParamsMode `form:"mode,omitempty" json:"mode,omitempty"`
+
+	// Key Comma separated key columns used to match rows in `merge` mode
+	Key []string `form:"key,omitempty" json:"key,omitempty"`
+
+	// DeleteMissing In `merge` mode delete dataset rows whose key is not present in the CSV
+	DeleteMissing bool `form:"deleteMissing,omitempty" json:"deleteMissing,omitempty"`
+}
+
+// AppendCSVParamsMissing defines parameters for AppendCSV.
+type AppendCSVParamsMissing string
+
+// AppendCSVParamsExtra defines parameters for AppendCSV.
+type AppendCSVParamsExtra string
+
+// AppendCSVParamsMode defines parameters for AppendCSV.
+type AppendCSVParamsMode string
+
+// GetRowParams defines parameters for GetRow.
+type GetRowParams struct {
+	// Format Output JSON format `objects` for array of objects, `array` for array of arrays
+	Format GetRowParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetRowParamsFormat defines parameters for GetRow.
+type GetRowParamsFormat string
+
+// ShareDatasetParams defines parameters for ShareDataset.
+type ShareDatasetParams struct {
+	// ExpiresIn Seconds until the link expires. The default of 24 hours and the
+	// maximum of 30 days can be changed in the server configuration.
+	ExpiresIn int `form:"expires_in,omitempty" json:"expires_in,omitempty"`
+}
+
 // ImportCSVParams defines parameters for ImportCSV.
 type ImportCSVParams struct {
 	// Url HTTP URL of the CSV file to import
@@ -85,16 +590,105 @@ type ImportCSVParams struct {
 
 	// Name Name of the CSV file when uploading directly
 	Name string `form:"name,omitempty" json:"name,omitempty"`
+
+	// Key Unique key column, validated to have no duplicate or null values and used to look up single rows
+	Key string `form:"key,omitempty" json:"key,omitempty"`
+
+	// Index Comma separated columns to create indexes on
+	Index []string `form:"index,omitempty" json:"index,omitempty"`
+
+	// Search Comma separated columns to index for full-text search, defaults to all text columns
+	Search []string `form:"search,omitempty" json:"search,omitempty"`
+
+	// NormalizeHeaders Rename columns to lower case snake_case ASCII names, naming empty
+	// headers `column_N` and suffixing repeated ones. The original
+	// headers are kept as column labels.
+	NormalizeHeaders bool `form:"normalize_headers,omitempty" json:"normalize_headers,omitempty"`
+
+	// Writable Allow rows of the imported dataset to be inserted, updated and deleted
+	Writable bool `form:"writable,omitempty" json:"writable,omitempty"`
+
+	// Visibility Who can read the dataset besides its owner, defaults to private
+	Visibility Visibility `form:"visibility,omitempty" json:"visibility,omitempty"`
 }
 
+// SetLogLevelJSONRequestBody defines body for SetLogLevel for application/json ContentType.
+type SetLogLevelJSONRequestBody = LogLevelRequest
+
+// InsertRowsJSONRequestBody defines body for InsertRows for application/json ContentType.
+type InsertRowsJSONRequestBody = InsertRowsRequest
+
+// UpdateRowJSONRequestBody defines body for UpdateRow for application/json ContentType.
+type UpdateRowJSONRequestBody = UpdateRowRequest
+
+// AlterSchemaJSONRequestBody defines body for AlterSchema for application/json ContentType.
+type AlterSchemaJSONRequestBody = AlterSchemaRequest
+
+// SetVisibilityJSONRequestBody defines body for SetVisibility for application/json ContentType.
+type SetVisibilityJSONRequestBody = VisibilityRequest
+
+// CreateViewJSONRequestBody defines body for CreateView for application/json ContentType.
+type CreateViewJSONRequestBody = ViewRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Show the storage used by the caller
+	// (GET /account)
+	GetAccount(ctx echo.Context) error
+	// Show the log level of the server
+	// (GET /admin/log-level)
+	GetLogLevel(ctx echo.Context) error
+	// Change the log level of the server
+	// (PUT /admin/log-level)
+	SetLogLevel(ctx echo.Context) error
+	// List datasets
+	// (GET /api)
+	ListDatasets(ctx echo.Context) error
 	// Query loaded CSV data
 	// (GET /api/{id})
 	FetchCSV(ctx echo.Context, id openapi_types.UUID, params FetchCSVParams) error
+	// Aggregate CSV data
+	// (GET /api/{id}/aggregate)
+	AggregateCSV(ctx echo.Context, id openapi_types.UUID, params AggregateCSVParams) error
+	// Append a CSV file to an existing dataset
+	// (POST /api/{id}/append)
+	AppendCSV(ctx echo.Context, id openapi_types.UUID, params AppendCSVParams) error
+	// Persist a dataset to Turso
+	// (POST /api/{id}/persist)
+	PersistCSV(ctx echo.Context, id openapi_types.UUID) error
+	// Profile the columns of a dataset
+	// (GET /api/{id}/profile)
+	ProfileCSV(ctx echo.Context, id openapi_types.UUID) error
+	// Append rows to a writable dataset
+	// (POST /api/{id}/rows)
+	InsertRows(ctx echo.Context, id openapi_types.UUID) error
+	// Delete a row of a writable dataset
+	// (DELETE /api/{id}/rows/{key})
+	DeleteRow(ctx echo.Context, id openapi_types.UUID, key string) error
+	// Fetch a single row
+	// (GET /api/{id}/rows/{key})
+	GetRow(ctx echo.Context, id openapi_types.UUID, key string, params GetRowParams) error
+	// Update a row of a writable dataset
+	// (PATCH /api/{id}/rows/{key})
+	UpdateRow(ctx echo.Context, id openapi_types.UUID, key string) error
+	// Get the columns of a dataset
+	// (GET /api/{id}/schema)
+	GetSchema(ctx echo.Context, id openapi_types.UUID) error
+	// Rename columns or change their types
+	// (PATCH /api/{id}/schema)
+	AlterSchema(ctx echo.Context, id openapi_types.UUID) error
+	// Create a signed link to read a dataset
+	// (POST /api/{id}/share)
+	ShareDataset(ctx echo.Context, id openapi_types.UUID, params ShareDatasetParams) error
+	// Change who can read a dataset
+	// (PUT /api/{id}/visibility)
+	SetVisibility(ctx echo.Context, id openapi_types.UUID) error
 	// Import a CSV file from a URL or upload
 	// (POST /import)
 	ImportCSV(ctx echo.Context, params ImportCSVParams) error
+	// Define a view joining datasets
+	// (POST /views)
+	CreateView(ctx echo.Context) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -102,6 +696,66 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// GetAccount converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAccount(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAccount(ctx)
+	return err
+}
+
+// GetLogLevel converts echo context to params.
+func (w *ServerInterfaceWrapper) GetLogLevel(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetLogLevel(ctx)
+	return err
+}
+
+// SetLogLevel converts echo context to params.
+func (w *ServerInterfaceWrapper) SetLogLevel(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SetLogLevel(ctx)
+	return err
+}
+
+// ListDatasets converts echo context to params.
+func (w *ServerInterfaceWrapper) ListDatasets(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListDatasets(ctx)
+	return err
+}
+
 // FetchCSV converts echo context to params.
 func (w *ServerInterfaceWrapper) FetchCSV(ctx echo.Context) error {
 	var err error
@@ -113,6 +767,12 @@ func (w *ServerInterfaceWrapper) FetchCSV(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
 	}
 
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
 	// Parameter object where we will unmarshal all parameters from the context
 	var params FetchCSVParams
 	// ------------- Optional query parameter "limit" -------------
@@ -136,6 +796,13 @@ func (w *ServerInterfaceWrapper) FetchCSV(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sortOrder: %s", err))
 	}
 
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
 	// ------------- Optional query parameter "offset" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
@@ -143,6 +810,13 @@ func (w *ServerInterfaceWrapper) FetchCSV(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
 	// ------------- Optional query parameter "format" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
@@ -150,33 +824,553 @@ func (w *ServerInterfaceWrapper) FetchCSV(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
 	}
 
-	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.FetchCSV(ctx, id, params)
-	return err
-}
+	// ------------- Optional query parameter "rowid" -------------
 
-// ImportCSV converts echo context to params.
-func (w *ServerInterfaceWrapper) ImportCSV(ctx echo.Context) error {
-	var err error
+	err = runtime.BindQueryParameter("form", true, false, "rowid", ctx.QueryParams(), &params.Rowid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter rowid: %s", err))
+	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ImportCSVParams
-	// ------------- Optional query parameter "url" -------------
+	// ------------- Optional query parameter "labels" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "url", ctx.QueryParams(), &params.Url)
+	err = runtime.BindQueryParameter("form", true, false, "labels", ctx.QueryParams(), &params.Labels)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter url: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter labels: %s", err))
 	}
 
-	// ------------- Optional query parameter "name" -------------
+	// ------------- Optional query parameter "columns" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "name", ctx.QueryParams(), &params.Name)
+	err = runtime.BindQueryParameter("form", true, false, "columns", ctx.QueryParams(), &params.Columns)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter columns: %s", err))
 	}
 
-	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.ImportCSV(ctx, params)
+	// ------------- Optional query parameter "exclude" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "exclude", ctx.QueryParams(), &params.Exclude)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter exclude: %s", err))
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", ctx.QueryParams(), &params.Filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter filter: %s", err))
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", ctx.QueryParams(), &params.Q)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// ------------- Optional query parameter "facet" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "facet", ctx.QueryParams(), &params.Facet)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter facet: %s", err))
+	}
+
+	// ------------- Optional query parameter "facet_size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "facet_size", ctx.QueryParams(), &params.FacetSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter facet_size: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.FetchCSV(ctx, id, params)
+	return err
+}
+
+// AggregateCSV converts echo context to params.
+func (w *ServerInterfaceWrapper) AggregateCSV(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AggregateCSVParams
+	// ------------- Optional query parameter "group_by" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "group_by", ctx.QueryParams(), &params.GroupBy)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group_by: %s", err))
+	}
+
+	// ------------- Required query parameter "agg" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "agg", ctx.QueryParams(), &params.Agg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter agg: %s", err))
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", ctx.QueryParams(), &params.Filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter filter: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AggregateCSV(ctx, id, params)
+	return err
+}
+
+// AppendCSV converts echo context to params.
+func (w *ServerInterfaceWrapper) AppendCSV(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AppendCSVParams
+	// ------------- Optional query parameter "url" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "url", ctx.QueryParams(), &params.Url)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter url: %s", err))
+	}
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", ctx.QueryParams(), &params.Name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// ------------- Optional query parameter "missing" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "missing", ctx.QueryParams(), &params.Missing)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter missing: %s", err))
+	}
+
+	// ------------- Optional query parameter "extra" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "extra", ctx.QueryParams(), &params.Extra)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter extra: %s", err))
+	}
+
+	// ------------- Optional query parameter "strict" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "strict", ctx.QueryParams(), &params.Strict)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter strict: %s", err))
+	}
+
+	// ------------- Optional query parameter "mode" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "mode", ctx.QueryParams(), &params.Mode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter mode: %s", err))
+	}
+
+	// ------------- Optional query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "key", ctx.QueryParams(), &params.Key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// ------------- Optional query parameter "deleteMissing" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "deleteMissing", ctx.QueryParams(), &params.DeleteMissing)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter deleteMissing: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AppendCSV(ctx, id, params)
+	return err
+}
+
+// PersistCSV converts echo context to params.
+func (w *ServerInterfaceWrapper) PersistCSV(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PersistCSV(ctx, id)
+	return err
+}
+
+// ProfileCSV converts echo context to params.
+func (w *ServerInterfaceWrapper) ProfileCSV(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ProfileCSV(ctx, id)
+	return err
+}
+
+// InsertRows converts echo context to params.
+func (w *ServerInterfaceWrapper) InsertRows(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.InsertRows(ctx, id)
+	return err
+}
+
+// DeleteRow converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteRow(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", ctx.Param("key"), &key, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteRow(ctx, id, key)
+	return err
+}
+
+// GetRow converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRow(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", ctx.Param("key"), &key, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRowParams
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRow(ctx, id, key, params)
+	return err
+}
+
+// UpdateRow converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateRow(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", ctx.Param("key"), &key, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateRow(ctx, id, key)
+	return err
+}
+
+// GetSchema converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSchema(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetSchema(ctx, id)
+	return err
+}
+
+// AlterSchema converts echo context to params.
+func (w *ServerInterfaceWrapper) AlterSchema(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AlterSchema(ctx, id)
+	return err
+}
+
+// ShareDataset converts echo context to params.
+func (w *ServerInterfaceWrapper) ShareDataset(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ShareDatasetParams
+	// ------------- Optional query parameter "expires_in" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "expires_in", ctx.QueryParams(), &params.ExpiresIn)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter expires_in: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ShareDataset(ctx, id, params)
+	return err
+}
+
+// SetVisibility converts echo context to params.
+func (w *ServerInterfaceWrapper) SetVisibility(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SetVisibility(ctx, id)
+	return err
+}
+
+// ImportCSV converts echo context to params.
+func (w *ServerInterfaceWrapper) ImportCSV(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportCSVParams
+	// ------------- Optional query parameter "url" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "url", ctx.QueryParams(), &params.Url)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter url: %s", err))
+	}
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", ctx.QueryParams(), &params.Name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// ------------- Optional query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "key", ctx.QueryParams(), &params.Key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// ------------- Optional query parameter "index" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "index", ctx.QueryParams(), &params.Index)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter index: %s", err))
+	}
+
+	// ------------- Optional query parameter "search" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "search", ctx.QueryParams(), &params.Search)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter search: %s", err))
+	}
+
+	// ------------- Optional query parameter "normalize_headers" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "normalize_headers", ctx.QueryParams(), &params.NormalizeHeaders)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter normalize_headers: %s", err))
+	}
+
+	// ------------- Optional query parameter "writable" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "writable", ctx.QueryParams(), &params.Writable)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter writable: %s", err))
+	}
+
+	// ------------- Optional query parameter "visibility" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "visibility", ctx.QueryParams(), &params.Visibility)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter visibility: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ImportCSV(ctx, params)
+	return err
+}
+
+// CreateView converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateView(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	ctx.Set(ApiKeyHeaderScopes, []string{})
+
+	ctx.Set(ShareLinkScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateView(ctx)
 	return err
 }
 
@@ -208,33 +1402,158 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.GET(baseURL+"/account", wrapper.GetAccount)
+	router.GET(baseURL+"/admin/log-level", wrapper.GetLogLevel)
+	router.PUT(baseURL+"/admin/log-level", wrapper.SetLogLevel)
+	router.GET(baseURL+"/api", wrapper.ListDatasets)
 	router.GET(baseURL+"/api/:id", wrapper.FetchCSV)
+	router.GET(baseURL+"/api/:id/aggregate", wrapper.AggregateCSV)
+	router.POST(baseURL+"/api/:id/append", wrapper.AppendCSV)
+	router.POST(baseURL+"/api/:id/persist", wrapper.PersistCSV)
+	router.GET(baseURL+"/api/:id/profile", wrapper.ProfileCSV)
+	router.POST(baseURL+"/api/:id/rows", wrapper.InsertRows)
+	router.DELETE(baseURL+"/api/:id/rows/:key", wrapper.DeleteRow)
+	router.GET(baseURL+"/api/:id/rows/:key", wrapper.GetRow)
+	router.PATCH(baseURL+"/api/:id/rows/:key", wrapper.UpdateRow)
+	router.GET(baseURL+"/api/:id/schema", wrapper.GetSchema)
+	router.PATCH(baseURL+"/api/:id/schema", wrapper.AlterSchema)
+	router.POST(baseURL+"/api/:id/share", wrapper.ShareDataset)
+	router.PUT(baseURL+"/api/:id/visibility", wrapper.SetVisibility)
 	router.POST(baseURL+"/import", wrapper.ImportCSV)
+	router.POST(baseURL+"/views", wrapper.CreateView)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8RWwXLbNhD9FQzaoyxRsqMkvKVKOnUnjV3L9sXR1BC5lJAQALMA5Wg8+vfOLimJoqjU",
-	"h3Z6kkgAu+8t3r7ls0ycKZwFG7yMn6VPlmAU/51M72/AF856oMcCXQEYNPBi4vLSWP4L35UpcpDxg0T3",
-	"pFPZk39o77Wzsiev0S1QGQOyJyfOemeMCtpZkYKYXMtZT+oAhuOEdQEylj6gtgu56W1fKES1pmf39SBd",
-	"wBJ2m+bO5aAsbftWAq7/MofYRv3xm2E0HL8djXoyc2hUkLFMXTnPCVodxZZmDkhB0D01Qe0wBBdUfhD5",
-	"fHda2wALOr7H7uZfIAl08AOiw9P1BFrurIIB79UCuiukDfigTEGre1YqwBkt7YltjxAz+FZqhJTua3++",
-	"VyPY55t1kLg0hcPwAxY2LZy24aBAchlCEQ8GuUtUvnQ+xG+iaDhQhR4MR+dw8Wr8+gzevJ2fDUfp+Zm6",
-	"eDU+uxiNx8OL4euLKIpk475K1MecXiiMFnX3lThv8R6Tpf3aZo4ip+AT1AXpVsby3fWlyByK3KlU24VQ",
-	"NhWsOXqYTO9FqoISmc7BE1gduAi0cPNheiveXV/KnlwB+ircsB/1I2ZRgFWFlrE870f9c9mThQpLrisX",
-	"61mnG3pYQDgGdQMBNaxAkHBFhs4IJQqElXalz9cMFlKGN1+Lu7vL95IzInfjZSpj+SuEZDmZ3nNiVAYC",
-	"oJfxQzsVHRYuE2EJzbAI3pWYkOg0bSPwsietMtwbZAv7+ldXVJnNgXbLknceybYN4qM2OjCEqmcJEFNH",
-	"CCVaSLcw+Gb2OHI6J5upjbbalEbGw65GbuedsO8JiiaCE95hEPP1iWS0Wh04yPiP5KYU1WEKyEI7CHMq",
-	"zxVtP0iTQqbKnIr6bjphsRPLB/n+Az/Sy9kLKn2VZR4CAynUQlsWzAkgjvd2lzd6SXmvylCUQfw+vfok",
-	"Kk2Ix6oj/SNDYCOmy67f9sQjv2qt8h9/AmUttu5a1XEb9dq/qaZAR9FmpO3KFLlfR1FUDUkboHJDVRS5",
-	"Trh2gy+euD430v+MkMlY/jTYD+NBPYkHzTHMrtQS5NZwsHaAVPgyScD7rMxznlk7cv8SosNRttkwKl8a",
-	"o3AtY/knVbvpDASP/XegeX7w3HC+w8Wq+SIUHyMHJbPyBSQ6Y3cFHZaAQom7m4+V54qdVX22Dml7gW6l",
-	"2ZjJHTgIdyvZdFlsTZvWKIm2/JecCXwQc5eu+5/tkTdWwF5gjr/d3l4zutogd0SCEzX7blGWmMtuR+ya",
-	"eced84k4tpM+LcE2SKcaIQn5Kbvinx8Z1ayycPDhF5euW3oK8D0MEr861NGOxlxbxcnaQY8UfdtkUCdo",
-	"M1lpJR4J72NbBvzx9l/1Yuv7pwP8tNF7jR74H9rwuJfqrwJWJ9bFrDh4wNVWzyTEE99scjPb/B0AAP//",
-	"/UKGzTEMAAA=",
+	"H4sIAAAAAAAC/+x9e3fbtvLgV8Hh7p77WNqWH3nUe/bscZ2kda/zqO2kt78ox4LIkYSaBBgAtKLk+rvv",
+	"mQEIkhIpy20St7/2jzYyH8Bg3pgZDD9FicoLJUFaEx1+ikwyg5zTz6MkUaW0Z2AKJQ3gpUKrArQVQA/w",
+	"NBcSf9hFAdFhNFYqAy6jmzhSV3gdPvC8yCA6tLqEuOuxuQSNT6ZgEi0KK5SMDqOXeJmpCbMzYAnPMtB/",
+	"MyzllhuwJmaQF3bB5sLOVGkZL+0MpBUJp9fjetqIZyKBKMxsrBZyihO/L5XlOPH/1DCJDqP/sVOjYcfj",
+	"YOdHeugmjjS3cJmJXFhz2ztn3MKpe/ImjkrDp3DbK6/poRucB96XQkMaHb5FDFb4iT2mq/Eq8NuAvQvL",
+	"VONfILE4/1FmQZ/TPGfwvgRjW2T5FCUzLqdIzbfICFmZE/p1Cpo94Ran0iB5TqPi1cvUXfUzPTm6eBrd",
+	"vLuJlzgjDPspEhbyW7HmQDymt6KbMDzXmi9WMFMN3rngogCZ9rNsChlYSFtYGIRxhLQwBY0DgUwLJWQb",
+	"X9HM2uJwZydTCc9mytjDx4PB7g4vxM7u3j4cPHj4aAsefzPe2t1L97f4wYOHWwd7Dx/uHuw+OhgMBlEc",
+	"TZTOuY0Oo1KLLr4U0oBeBvBgrwvCDUWsLFK+POD+6nhd3Bdw0ICrHjAOyOwixPH5m34qOE4zLZjeRpda",
+	"zQWO+1wY4yT5lVZTzfMcojg6VtKoPCchZymw41c4cWCvFVS2eSiOJjwBa1aVzRuelcBI1ZlK52gnLJAy",
+	"9xZT16DpzkSgSEHKtJqbKN6MvZ/hIF0wSfhgVyE6LrVRmk2UmxIfYgWfQsxULixCpSTdybhxd1pKDxY/",
+	"fDz5RYl/7/9wne5lVy/zZx9//liYn//9YnAi5iIVJw9/+vh6/vzZ7jddPLghY70vQS8u8zYV97YfPt4d",
+	"7D78Zm+vweypKsdZQ23IMh87JiYsHn5aQYyRoig6yfVMaWaA62QGJiYs5NwmMyGnbK50SiQEnsyQQH8z",
+	"/lHmOY5dwQLSoRwv/BWGyi1mwuHT8BwY6TnGDRshcKNt9hzHB8O4BjbXvCggZUIO5WhYDgb7Sc71Ff2C",
+	"EbN8ahiXKY1mkW7CsO8vnp8yMAkvIN0etgzU20/RhbD4K7qYAVse8HzG52bGpfsz2anvsDNIIXc4uWmK",
+	"AU9TgVd59qolcn3iUUvsCgnK6ZRk4LJPco49UtEQswnMWSqMFTKx7BplyjA745bl/ApYaWBSZl6aWhiI",
+	"vgOpIYqjc8ttae4m01ZZnm2g2lbVEzf2qdZK9yspwNudUEy4yEp9B/OGsz1zL3WtIgdT+QmrKxY5GMvz",
+	"Au/WAsUtbOGtVfldUuT1+7FfUT1fYyXvenBUQd2jwtvWseU3rKyENOwqE70gXYBS22SahEupLBsDS7ix",
+	"TYZp6hUh7cODqMs4VnqlPdcFKXBtLMOFo8pY1uLtRTqD1Fzj7qONpqeltF6M9nd3Bns7e4O9gxUaVXbP",
+	"vfVuA9l0fzeHJ0/sNmbwRPOPVSTx2OpkAXrhlVYTkXV5U17eLwNta0QdDDbCVM4/rNLplGtUPY4jWrZt",
+	"d2+wPaD3gMvVF58Dl8hKssxBi6RS+80hDva3H91umuLow9ZUbeHFLXMlii1VOK26RR4R6OhwwjMDCIno",
+	"AOQ8x11L9xK2H5DpJ6+6ScJXumezIsss60DxZpJg6OlLJ1xLHheCEnucxtFj/Kehfrtsgk1TuO5YLg3J",
+	"jOUy5TplKVwL56itJ8aD/e2Dg89KDauKxlo3987IC9xQ1F6+/vb0dmEjAgdRa9AwXpYbx0NOFlorWKZe",
+	"l4g+cbviDg2tgaP15nZT09He9dy2VUGVsMrEVnNpeILkMduJue56U6Tt8UuRdj22SXDg6NUJ+nTeaLgV",
+	"0w0fLOgatwBthPF7olW/9o/g91zjEpRsoXGNNRIw7zaGHktMGMYZPsZ+UUKiYVR2Brq6b6KuDcC1MGIs",
+	"MmEXt0nYm/rJmziaa2H5OIMu9C/JEDFGYLTWjrTB3k2SNob3C28BWqNujSydCrMm6hVwsql+8aN20XGj",
+	"rVbX1jwAsWYZty7hDoD/VkA74fzVDvh9Ocxdi3B7+438Yy/pHSrJ6lImVZhmVUzdYGzGDcuVhoaakWwO",
+	"GpgGW2pJ7B+mI4vYKbefzzz2+ZfBftUL68Xdm8pXXkbgsr9zsHc337tLaTvUxQzNMQVY0PWtI8kd7pqZ",
+	"YSQiXVlr9aSDs2t1J3mh9BopvKcQ468W5QBv52IpQHim5qYRaG5TAG8ybph7ycdiWDsUw5RmxFumsSMU",
+	"snqEYjNLAZQjKXKeoWNgLd08FRMwBZeXPwPXJjrc3buJ30Y/qWyCUUQuhWFZWaDJXe/s/qCE/BcsOvaF",
+	"GUxsL4epCeOSAdeZqC1oTAElt4oRLnLEM8HNtr/SRSctprO1s6Cbg+Ya0n5vZ4mOBHg1dBcZT9X0FK7B",
+	"xVRkmeNLKYzLKcWAJwrtK9eyoRvVZBK9W5m3HqnBDstI9POsUz4BntWV4NV1S+gXvDvO/BtEph9Kv6fu",
+	"BzLhyazPHhTuZTbnhiHopXWC1OA7H8EOQWQKbVbuXuUDbWItGrH6zWJdrZBBh1hNQYK+885kw6C0VvOu",
+	"YMRgsFk0ouFXt7H+pI05ZvuoMFEt/bS/wbRdjOOpX0PUXNoSDmsadTHaj1Wmc2nHbpXmU2AueVhnFuYz",
+	"kKyUdBnSbXaEmUcX+5bKsup6tJzxy/mHy/HCLoUYdgeP9h8d7D7eO9g0GHTZdK+bBNwsrhvSr6sCNS51",
+	"p1VygmIYzzI1h5Rxy5RMWk7AXmeKEAmwZrwCNDOQKNnyynYHG+RFluODLqLqFvBu3ao7NqvuesiKJJkA",
+	"afvovUJWQT4M/uJZ9nJCBnej9DdlJTaO2mjg6ReepJNbyF3pLW+YTCBZTp3u/upUbJeYhym6qNpKiq/Z",
+	"YKwkDzVI23SqOr2LELtZCsfD/LZXq4jYkl1aFMCsoni9q9two1h1yL59+fL06dGLmF2cvPj55MVFzM6f",
+	"H52e0q+TFxdPv3t6Fg/ltyff0ZXvX3/3lH68Do+/rp9/Xb3AXlcvPDt9eXQRD+XZ06PTmLnwXMyePD0+",
+	"eX50+vciNv+I2Zujs+Pvj85ihoFyBOS5///5xdHzV/FQht8X/4X+2evXJ0+GctOI+hryBTK1yXcFi7V7",
+	"PWGaYay/GVZK8b4EdJo3MtsZH0O2OsP3wNNGaY2fy9kzJ+tow0QGMTPgvIdKIdf3Ke41kqjFMvERLmc0",
+	"qBlF8cbJoNXAIT1/2ft8mWVVzOhWJ+BXZkdWA7Y+jIQ476fwRmUOd6mCobc+a7RonX9wPuN6jRsKHwqh",
+	"wXwJX63U2efc+v4/gyv5v7uPHj7a23vwcDDY3n+/t/Vofv79i+Lip90f5ZuP3z3Qu8lP+68+fjP9OX14",
+	"9f71bHY1mP3XN/8+unXf3IVWXEDcxFAXfl9TucyZmjc2Qt1Jeoen/qiFQfVKe8munXNT+D5F3/OxsLSa",
+	"c37NpeSdKf/XVRRtyVOq/Lgl7YTJdmbER6j0x5MyuXryLemLUDrTCBnXaZ69g73Hjzf0wZsOYKcT7ios",
+	"MLbbmmR371bXugGbW2MXwd4ImPcmVWjX3mE1eU6x/hRJpGECGn/YZoRd0l4dSefg96QrNEzEBzAxS2HC",
+	"y8wSlYU1hNVeG7xhCgWn7DACat6Gzfg4whIQ0hUfVnGA6u9WECGOJmWWdUYAlNxY84VQy20RRpH20qy7",
+	"wLHmpreBeq6UkfL+aXQYDcbfTAbw4NHW/uQg2ToYjw+2HqcHydaDx3wv2YXd8f4EU87160lprMobIzzi",
+	"u8leug9bB5MHg60D/nC89U3yON16BA8nD/jBeD/ZQ/I4alT4Q/S8rWJJYcxLkQbcHuLoN+/QyW3WYJpL",
+	"NMCXNRQr5ZcbiJBVRPNt9hR3Bf4Fxic21Llp0+AMz85DqSQYNoaJ0sCE3WZVLgwBTFkmroBxlqyJhA0l",
+	"11CxvfclmsxISCYJ4bgxwfUmSoMrnNqImZoC3FVtwy1oQZ6LD68QywdfalkBF4tmpI3ixUIaCzzFBYZ8",
+	"mWRwDXrBqCyuM1/W43BT1NPpTtRpsdMj3KwogdpQdrPBbTZrbc7oTSuZ1wbxpxm69ZLhLo3xQKkxGJGC",
+	"g5NytY5qFDA4ZFKNVbqI2UxlCOxQIjcwstEsE/IqplCvXCgJQ9nQMoUW184JxF2pz+kV5TgTSfSuiYTG",
+	"7RXVU6+mN/z467KXy0mA+tYqUm/iyEBSamEX5Nm5eXkh/gUL54evorrKZxuQtvLLR//eOnp1svUvWIyY",
+	"c7UpGBsdRuEvrx7CgzVO3HSIkzFwDfqotDOy8fTXs8qA/PDTRRT3wKI0++GnCyaMKZ3X4bYOciKmpYaU",
+	"iRSkFXaBAbFrkYKOHfjcDOUI51NafKRqkEP2LU3rCx2tugLpSye32QX+ZZgEn8E3iSrA6xU36VCOPmzR",
+	"5VGIZyBJ/djInrHfpSDQPim8zXBbRWzpxhzKqebSGi+wYYRahj2HO51DbECiTLDXqEV3FRFLPH0q5FVH",
+	"lE1MnfK8AsmWBCCUK7jt1KuX5xeMnN1PIr3ZoedGsYeVStVI48hswXiSgCEVriTUerXiikoDeaagkVYZ",
+	"4ubGR/Q7eRA3fpniKU6MUk1j4h/H529oRuf04bC+fhVvnD09v8CSjEbcEuudfMmWKkDyQmAt3PZgex/F",
+	"mtsZCcUOT0LUdgodUbrzmfdZ1OrRkLjldxK43jE1PshpZ7AYytJAMDdCMzpDEYdqXc1tFQ1lvCgy4Yxe",
+	"I2jm2CGwy0kaHUbfgfXHZOiohNtF0ZL2BgO3B5QW3MJoVHdIZecX47wjp2Nu00DLJ3GIeEtE81DcxLVB",
+	"+0yzt9P0Nzc0uynznOtFkzQVtksTJNZTiAq4SPaiQxfrwyF2SCZ3MjXdChmZTuK/RKY3oK+reKUOMVsN",
+	"zeM/LjC9QqGQyvmCJFrJOnXQ6FRNWVbllO6LSlkFRCVEhNg2iYgw5FaWXRlIikguDVal0DUYyzUprHrw",
+	"mPSJN93MqqGknCLjaDLGGeRORzdsihvUyaFhfMqF9M4pr6bYHsq7MEaH8J4vsQa9+61KF1+AK2hsxxS1",
+	"A4E7/ps/MVN2ctKtbEmqoxC96gLLt1ZNAsULlg2HhDklSXGz43NdaL+Gkrbp2cpAauI9BDJDXTyFkz+p",
+	"wwxfjLRdpWod1A2gfH3iEhkaEZdOC+DdnV5anoHVAq7B7bsmWuWkNOBaqNJkC/JRICWvZLygoP0KRZ6B",
+	"TWbH52/I39A8BwvahQPaU+HLFX80htVgVKkTqLwr9Flq58rt1lsCHTfQd0uA5iZeBoLSWgSCDOciaOmN",
+	"Cq8uJ48cl6g5dS6kyMu8M2O1Ou9xowTHKmbQhR4veibDu8dVqVc9462LO3eOeQrubFtrmL55XvqKn3qa",
+	"wMbR0flxY+P45Cn9iRffbYDpY5XnnBlAnrCQuiWj2MfB/RO+4GibvaJwRR3ZcC771mgoK2Th4CCDv2zK",
+	"Cb4grH/0ENMJ5pIUjav/8Vcybqwbpsh4Aq4wzYV7YzafiWTmxsfnkMX96rfZcX1CRuVjIfFYm5urxuto",
+	"6bhZdMwtTJVexFt02KAJVexTL32EuBupX04mVf6o4FMhSRxjlqwA7fGT0JHHUc/8ikbrZu/BJuz9suCY",
+	"PEv8wUpUI7SzxqOVIzYRkKVue1apFlYp7m12RpJnhhLfIFmso2REFa3mTm9wdxiTlSa4PyhPiL3t3u2Z",
+	"g+mO2C1tUVr2w/nLF8ypGDbyVXUjNqlK6BAofzVmI7q0dJd+mB7A3MA9oufHbYhffYVG3UgGySlVms1E",
+	"6ryAkTsDPAoVrypLAy6pqpuw7SIOEwG6B/bqRFUX6Gam5g24/Z8IwkYwU7yOWMFr5HC2dPlQqdLVLsjF",
+	"aSgggtXpFrpzrmvSrUPZkW/t5SlK/pqe9YdSerd+/6fSAsU0+1W6s8KAVR4rcUN3nljIDcUPeWYUG8NQ",
+	"hmqp6sUKK1gkCR8KDXQAHP+kcPAo9jLlnh9KBNrElY0khUvAskxY0DxDhv/fbIv9k+2w/zWK2eg//xnF",
+	"rOAaUIoNmKGs9v2TUrrTKoyPTcwSxTNU5THLQE7tLGZYFaRjllktclc4LCbxUGoghR0zrUqZxky7+6Yc",
+	"G6tjhn8RYGVRgF7Ww6+0SsvExkEfkzr+548ld5E0bpg72tqjM8JJqjsojTVEy4BfA8PtWzj/bpBZumeH",
+	"D0lWpnC32c/U3J+db1PbQXGoikMyeqMY67AQDGf78tJYd8Abt3vk1ynttnfwPmYSYja1+B8SzOJ/gDSU",
+	"lgtpYkYbRYMCFA8lyNT9ROZE0khlhWR/98nVGjPjBRv9Z/SPmAlD5tg/i7+Xz28Hi3oI7w+/VerKUNcC",
+	"tK1TC4e77kBdl3YlXLRwuOnxn1XkPiuzbIuOm/vj7qFdQV0/0j4I70+1V0dgXVJGc3nllq8hg2suib0r",
+	"NVdFoy8pH1Op6BiLx8CYoTROW9HBobkwUEfWvCllQhLjGDYT01mGKS5IWXXSf1lETHX2vYcL39+V+wO3",
+	"U6CMIMuVseiJ5Cqk2NWkRt5yr4f1h7e6iExnQz4PjVfOSQfCFOhP+5l6objEvH23RcCSxJx/8LsFzNKv",
+	"3Tu8+4K72mbXkI7dbAg+a78tREebguGYhF7cwyb3R0R1c7uI4N2y2d3h06mGqa8e7dz2fqdVWbDgcIYO",
+	"FzVXmsq3GE3x2cvxYhQ0OpdpsLEsTGZcEA7j2fTKNjuqbtVv6irjQj7uUDYtZKNsIWawPd1mI1Pml6Tt",
+	"Ri6QPiLZcl6m+334z1FXmCRM/UfZmK+xnoRNNl64olq8EnBOxmxZewSb0SOvFUF/m3lv0L1pbytiHvqT",
+	"J9vsmb/ijQBRLXb/XFYHW9GvyWPGr6cxy731zPmHuCaye8HQYldUeZkfEpfE/umYX08PzzhGiXtwwKfT",
+	"tfT7y9+4T3+jK0pFXPvF41R/0J3vPdrMoGnT35nprK3PxnaTmrvhtIXqOsrhmr/VZhPpTIO7guapuHZK",
+	"gLPXZ6cupc2C5cHtelk4+zKU3tmtTlFhKYuLVPG6JCcmVS8ZfCAtOQ1lA6EoimsYStImTsp9HABfJiGv",
+	"rINLVxH0ztHmDLe7GbBGO4VOM0rv/FFs6PcXF68I9R6QijSER0fbboF0hbZdM2uxycTNGqswqTv5UlRV",
+	"DqnQkNiszyjTP3eyQN9zmVKPITWpOCN4DbkwSOA6Enl8/iZmI9T4GI7MMqr+zx3L4VXUY3TicsQ0uOOz",
+	"3hfsi9n6KfqCQFi02QgC+T9xho0iQM3FIUarhUkVypbCydc+wGM2ElNJe8lUq8IvmdxInqYjxtPUX2vX",
+	"0vaGJazmPcutDqtW663+dvNHccTTdKN1/wugWKImw5foYDOWmaAob7NvQ6B+6SFsISdSoGj938mHdodY",
+	"cInu4ArqIX9Y5R+OR4Ni8I5DqgjNE2HJs0BgEzuUuUpDZwCcxx3EcZgLKsqDIdM13baGErtj0Xtu6v7g",
+	"tZu7G+k9R1NWcTpys3iCB51Yy+s2G+WgpzBynhkY/4APSFxBvfeJh5L6U+Jaw6LDGX/G660UVrYhHlxn",
+	"y2pzRdELs82eYpZ3KDHYLAFSU7UGCOdp8fVqzqBTTC2wvtBbg2d7qkA4gy0X1fW2KVFa0z16m56mhSJU",
+	"y5SrmG4GGuKhHO24kUaMZ3O+ML6aDAGVMK+e7qccckuPsARVXElLuEDA/aoIcQNdoU6eSFEV1NYkdoDB",
+	"hyLDXxUbda3BnYW6u0MbR8YussrTi1ahP2mDw1yj00ACx08zZRwbCKf2Cg3Nqk1nlLvAdqM9X6ef+2Tn",
+	"3boyFQwA7iTmuu2hBVs5FpI3K5MD8VZcxoummfQTLJvLa8HZCBc0Wnakoi9Z0bLU37cD+POGV1urTqTG",
+	"Pbi4NH3DCe3xGFuOr5PrJdfX17L2+76hTr32fCt2JSfDV0QGvXKBaUd6ZMwN+FTzUFLVjWHCBteEymJR",
+	"UV4BFHRnDHYOIH2FTlWOZbr801cO7N+pg/old2VLzV/6S3NY3bvq6zOop0+DWaxyrNFiyYr7lniybgvZ",
+	"V7tTasmM5RbZPfEBSKqf8mEY8kd8Nx7ksdBIzUWT4qFsRZty4O6vjkaDOPZSp8E4BPmHsh3lpzFcNaK7",
+	"giUeyplw5zvRlq2UVmRNqXIB0eD/UflaJ9+7wf6EfL/c12Qd49c9Qr4227uZG2eg2yrztkBE1V12bRgC",
+	"H6pjDhSxurXzkambHw1ld/ejbfam4eMr0Ek4ndXeazimGkq/pv/jZ68yCgsXrQ6i4uKfXLs6bncCqY5A",
+	"ttm7bvb0u2Xvz1/Lu9rh6itX87a6VXTI1Zk/muYa9d+bt0P8ZRXjrOrEuLmbg+/ufLqCxU39sQb81Wa/",
+	"J3T9TM1/j9wXd39foH06+m+msSOiSqFW9RP52o3HqfOfVI13ukF3G6LNsybv7pdbWfUBia/Pq46FGK+K",
+	"9zZk1rjb06ES48pPqKvTSMULp+99+MB1HGSi+6DKXyz9m1n6r4zRb84YoWT+brJETrRCPkSreUswnXOG",
+	"MmOT2apguuYbLT/PeWRd+Z0qtLcqwxRiXJXY0NrjL6H9DHbo83tsK61Xfn8OG6u+pvT1JcvLxl1NYMtf",
+	"qwHxdnHFpLmeRn+2XfBSZ6g1m2Cvlu6B/t+BvcsGuFfHHhVFFnayrq2c/wbSonAJJdOb0mbH3J/WHMpk",
+	"BskVpUK0sYdMTBiXldvUPDcir2mDw6SylMgRxgdiUlf4RunFxuddfBLGFdNXufl6jxzCb9XazVA2pnMj",
+	"d+be62/r/Yl2wR1fFPzKWvV22QrlF+GcTvXJwJs4Otjb+3yO1MpXpDqgedObaK3CNvXX5ogr70EXnJHY",
+	"1qpAe4z5VgkOrNutAXWa6E9RUIaScdf9Ym1fC+56khJOlGau/UsIZfm4rLDM916L6w7usvokiDtSTlPN",
+	"lb4ylXtXj1L1Mydl5cLSBEu4PoYMXUEbU2/llPJeAvXDyHXnWM58dZ4xxyefBK36+3cTz6llrWnEvgmH",
+	"HtMOq547Eda9AzZTpQ5fvRtKX0qON/cHLKXcNJcNZRq+tufyR9Wpf14XOXWXd7g2e0J2b7MePzwYNAvZ",
+	"9x58sze412L2dmfFrlRl6AVzH2fvK2k0rkcNUdmqpSZPtwt9u49SZ8OIV66lU+M8voamxIuxy4w6ZbPc",
+	"SgpLOoIukNA6Suag9aUdjdY6+LZTGu6V8WIoXa+pblXxnF+hcIdlM9+FigmMwouUKitI9OtJzDarO0Mx",
+	"YdyKQE4UheRp7+QnqHwQJ6Q9rSjeNL9S82dxJ1YbdX1lb2L5WzlrXHViM2ENFdhctz5pdD99M+adfdl6",
+	"Rbbu5d1tn13KulmtMF4wU0AiJtT6CQR9E6qzenYo3WlX1wKscVQF6mrXunikqsPsiMJ0ppsIsA2SqesK",
+	"TP3q/xsVmL4OHaFDAKdSVpQRnOGhTqlYWjo2pOhzo7+AI4ovxsqUumJl0Yiz9cU6l6uufsvRGVe0xoRM",
+	"4QOgBt2w8ote+DK1X2ugpVnJj5wsHbhsN07FEka62fzi4e2LckN9mVUtOfhE8Dk6X9wAM5JfwSX9PDo/",
+	"Pjlh1fFqnpPc54VdDKU/dV4dobl8MWp0unCOfOEa2qHJdY5idaq8fhsN4RUUltFHM3Ag5k6r93t+Kwff",
+	"f2O96RF+6KFVYxqO2jeqYMYQcqlxFaWjBVdJq25gG1+f+y0wtlpuNiOwK00326xXd8/sAq71Iby7Gui/",
+	"yg83qepqlR/Wztk9OAmr5ty3TyIDWR126fUWXH/tXmcBOzYzO1c4En2Tru6RJVvVvlRxyBu7fBzY97Tw",
+	"TMxOnsRERhFaCMtF+wuUdF49tO2lMVB/FAsX72s25yVO8b01Y3YttC155t/4RXmXI/FfyWjWdQndWkSj",
+	"ne82o05z4a6vkBHSl1XGTCo7lHUoETVgXG17HXBdjo3bg71xX6n8Mu413Ffy43ZRQeAqQt1LCcBESFj+",
+	"7mlnw7IgF42WvuR+Nnvqvn2Harzd3tddazSIfUvdvF3kw3mw9BWE1U8f7OPH/G7e3fz/AQCBy/+HoIcA",
+	"AA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+// ListDatasets implements ServerInterface.
+func (h *Server) ListDatasets(ctx echo.Context) error {
+	tables, err := h.db.ListCSVTables(ctx.Request().Context())
+	if err != nil {
+		return dbErrorResponse(ctx, "List error", err)
+	}
+
+	datasets := make([]Dataset, len(tables))
+	for i, t := range tables {
+		datasets[i] = h.datasetResponse(ctx, t)
+	}
+
+	return ctx.JSON(http.StatusOK, DatasetListResponse{Ok: true, Datasets: datasets})
+}
+
+// SetVisibility implements ServerInterface.
+func (h *Server) SetVisibility(ctx echo.Context, id types.UUID) error {
+	var body SetVisibilityJSONRequestBody
+	if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	csvTable, err := h.db.SetVisibility(ctx.Request().Context(), id.String(), string(body.Visibility))
+	if err != nil {
+		return dbErrorResponse(ctx, "Visibility error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, DatasetResponse{Ok: true, Dataset: h.datasetResponse(ctx, csvTable)})
+}
+
+func (h *Server) datasetResponse(ctx echo.Context, t *db.CSVTable) Dataset {
+	return Dataset{
+		Id:              uuid.MustParse(t.ID),
+		Filename:        t.Filename,
+		Endpoint:        h.datasetEndpoint(ctx, t.ID),
+		CreatedAt:       t.CreatedAt,
+		Persisted:       t.Persisted,
+		Writable:        t.Writable,
+		View:            t.View != nil,
+		Owner:           t.Owner,
+		Visibility:      Visibility(t.Visibility),
+		Version:         t.Version,
+		SuggestedFacets: t.SuggestedFacets,
+	}
+}
@@ -1,13 +1,14 @@
 package api
 
 import (
-	"fmt"
+	"context"
 	"io"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/JayJamieson/csv-api/pkg/metrics"
 	"github.com/JayJamieson/csv-api/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/oapi-codegen/runtime/types"
@@ -26,55 +27,179 @@ func (h *Server) FetchCSV(ctx echo.Context, id types.UUID, params FetchCSVParams
 		return errorResponse(ctx, http.StatusNotFound, "Resource not found", err.Error())
 	}
 
-	columns, rows, total, queryTime, err := h.db.GetCSV(
+	result, err := h.db.GetCSV(
 		reqCtx, &db.QueryCSV{
-			ID:         idStr,
-			TableName:  csvTable.TableName,
-			Limit:      params.Limit,
-			Offset:     params.Offset,
-			SortColumn: params.SortColumn,
-			SortOrder:  string(params.SortOrder),
-			Format:     string(params.Format),
+			ID:             idStr,
+			TableName:      csvTable.TableName,
+			Limit:          params.Limit,
+			Offset:         params.Offset,
+			SortColumn:     params.SortColumn,
+			SortOrder:      string(params.SortOrder),
+			Sort:           params.Sort,
+			Format:         string(params.Format),
+			HideRowID:      params.Rowid == Hide,
+			Cursor:         params.Cursor,
+			Columns:        params.Columns,
+			Exclude:        params.Exclude,
+			Filters:        params.Filter,
+			Facets:         params.Facet,
+			FacetSize:      params.FacetSize,
+			Search:         params.Q,
+			OriginalLabels: params.Labels == Original,
 		},
 	)
 
 	if err != nil {
-		return errorResponse(ctx, http.StatusInternalServerError, "Query error: ", err.Error())
+		return dbErrorResponse(ctx, "Query error", err)
 	}
 
+	ctx.Set(rowsKey, result.Total)
+
 	resp := CSVResponse{
-		Total:   total,
-		Ok:      true,
-		QueryMs: queryTime,
-		Columns: columns,
-		Rows:    rows,
+		Total:           result.Total,
+		Ok:              true,
+		QueryMs:         result.QueryMs,
+		Columns:         result.Columns,
+		Rows:            result.Rows,
+		Next:            result.Next,
+		Facets:          facetsResponse(result.Facets),
+		SuggestedFacets: result.SuggestedFacets,
+		Snippets:        result.Snippets,
 	}
 
 	return ctx.JSON(http.StatusOK, resp)
 }
 
+func facetsResponse(facets []db.Facet) []Facet {
+	if facets == nil {
+		return nil
+	}
+
+	resp := make([]Facet, len(facets))
+	for i, f := range facets {
+		values := make([]FacetValue, len(f.Values))
+		for j, v := range f.Values {
+			values[j] = FacetValue{Value: v.Value, Count: v.Count}
+		}
+		resp[i] = Facet{Column: f.Column, Values: values, Truncated: f.Truncated}
+	}
+	return resp
+}
+
 // ImportCSV implements ServerInterface.
 func (h *Server) ImportCSV(ctx echo.Context, params ImportCSVParams) error {
 	reqCtx := ctx.Request().Context()
 
-	var reader io.Reader
-	var filename string
+	reader, filename, err := openCSVSource(reqCtx, params.Url, params.Name, ctx.Request().Body)
+	if err != nil {
+		metrics.Imports.WithLabelValues(metrics.Status(err)).Inc()
+		return sourceErrorResponse(ctx, err)
+	}
+	defer reader.Close()
+
+	csvTable, err := h.db.ImportCSVFromReader(reqCtx, filename, reader, db.ImportOptions{
+		Writable:         params.Writable,
+		KeyColumn:        params.Key,
+		IndexedColumns:   params.Index,
+		SearchColumns:    params.Search,
+		NormalizeHeaders: params.NormalizeHeaders,
+		Visibility:       string(params.Visibility),
+	})
+
+	if err != nil {
+		return dbErrorResponse(ctx, "CSV import error", err)
+	}
+
+	endpoint := h.datasetEndpoint(ctx, csvTable.ID)
+
+	return ctx.JSON(http.StatusOK, ImportResponse{
+		Ok:       true,
+		Endpoint: endpoint,
+	})
+}
+
+// AppendCSV implements ServerInterface.
+func (h *Server) AppendCSV(ctx echo.Context, id types.UUID, params AppendCSVParams) error {
+	reqCtx := ctx.Request().Context()
+
+	reader, _, err := openCSVSource(reqCtx, params.Url, params.Name, ctx.Request().Body)
+	if err != nil {
+		return sourceErrorResponse(ctx, err)
+	}
+	defer reader.Close()
+
+	result, err := h.db.AppendCSVFromReader(reqCtx, id.String(), reader, db.AppendOptions{
+		MissingColumns: string(params.Missing),
+		ExtraColumns:   string(params.Extra),
+		Strict:         params.Strict,
+		Mode:           string(params.Mode),
+		KeyColumns:     params.Key,
+		DeleteMissing:  params.DeleteMissing,
+	})
+
+	if err != nil {
+		return dbErrorResponse(ctx, "CSV append error", err)
+	}
+
+	endpoint := h.datasetEndpoint(ctx, id.String())
+
+	return ctx.JSON(http.StatusOK, AppendResponse{
+		Ok:       true,
+		Endpoint: endpoint,
+		Inserted: result.Inserted,
+		Updated:  result.Updated,
+		Deleted:  result.Deleted,
+	})
+}
+
+// PersistCSV implements ServerInterface.
+func (h *Server) PersistCSV(ctx echo.Context, id types.UUID) error {
+	if err := h.db.PersistToTurso(ctx.Request().Context(), id.String()); err != nil {
+		return dbErrorResponse(ctx, "Persist error", err)
+	}
 
-	if params.Url != "" {
+	endpoint := h.datasetEndpoint(ctx, id.String())
 
-		reader, err := utils.DownloadFile(params.Url)
+	return ctx.JSON(http.StatusOK, ImportResponse{
+		Ok:       true,
+		Endpoint: endpoint,
+	})
+}
+
+// sourceError describes why the CSV source of an import or append request
+// could not be opened.
+type sourceError struct {
+	status  int
+	error   string
+	message string
+}
 
+func (e *sourceError) Error() string {
+	return e.error + ": " + e.message
+}
+
+func sourceErrorResponse(c echo.Context, err error) error {
+	if srcErr, ok := err.(*sourceError); ok {
+		return errorResponse(c, srcErr.status, srcErr.error, srcErr.message)
+	}
+	return errorResponse(c, http.StatusInternalServerError, "CSV source error", err.Error())
+}
+
+// openCSVSource returns the CSV data to import, downloaded from rawURL when
+// set or read from the request body uploaded as name, with its file name.
+func openCSVSource(ctx context.Context, rawURL string, name string, body io.ReadCloser) (io.ReadCloser, string, error) {
+	if rawURL != "" {
+		parsedURL, err := url.Parse(rawURL)
 		if err != nil {
-			return errorResponse(ctx, http.StatusInternalServerError, "URL fetch error", err.Error())
+			return nil, "", &sourceError{http.StatusBadRequest, "Invalid URL", err.Error()}
 		}
 
-		defer reader.Close()
-
-		parsedURL, err := url.Parse(params.Url)
+		reader, err := utils.DownloadFile(ctx, rawURL)
 		if err != nil {
-			return errorResponse(ctx, http.StatusBadRequest, "Invalid URL", err.Error())
+			return nil, "", &sourceError{http.StatusInternalServerError, "URL fetch error", err.Error()}
 		}
 
+		filename := ""
 		path := parsedURL.Path
 		for i := len(path) - 1; i >= 0; i-- {
 			if path[i] == '/' {
@@ -86,26 +211,16 @@ func (h *Server) ImportCSV(ctx echo.Context, params ImportCSVParams) error {
 		if filename == "" {
 			filename = "downloaded.csv"
 		}
-	} else if params.Name != "" {
-		reader = ctx.Request().Body
-		filename = params.Name
-	} else {
-		return errorResponse(ctx, http.StatusBadRequest, "Missing import parameters",
-			"Either 'url' or 'name' parameter must be provided")
-	}
 
-	csvTable, err := h.db.ImportCSVFromReader(reqCtx, filename, reader)
-
-	if err != nil {
-		return errorResponse(ctx, http.StatusInternalServerError, "CSV import error", err.Error())
+		return reader, filename, nil
 	}
 
-	endpoint := fmt.Sprintf("%s://%s/api/%s", ctx.Scheme(), ctx.Request().Host, csvTable.ID)
+	if name != "" {
+		return body, name, nil
+	}
 
-	return ctx.JSON(http.StatusOK, ImportResponse{
-		Ok:       true,
-		Endpoint: endpoint,
-	})
+	return nil, "", &sourceError{http.StatusBadRequest, "Missing import parameters",
+		"Either 'url' or 'name' parameter must be provided"}
 }
 
 func errorResponse(c echo.Context, status int, error string, message string) error {
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/logging"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rowsKey stores the number of rows a request returned in its echo.Context,
+// for the request log.
+const rowsKey = "rows"
+
+// maxRequestIDLength limits request IDs sent by clients.
+const maxRequestIDLength = 128
+
+// GetLogLevel implements ServerInterface.
+func (h *Server) GetLogLevel(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, LogLevelResponse{
+		Ok:    true,
+		Level: LogLevel(logging.LevelName(h.logLevel.Level())),
+	})
+}
+
+// SetLogLevel implements ServerInterface.
+func (h *Server) SetLogLevel(ctx echo.Context) error {
+	var body SetLogLevelJSONRequestBody
+	if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	level, err := logging.ParseLevel(string(body.Level))
+	if err != nil || body.Level == "" {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid log level",
+			"level must be debug, info, warn, error or off")
+	}
+
+	slog.InfoContext(ctx.Request().Context(), "Changed log level",
+		"from", logging.LevelName(h.logLevel.Level()), "to", logging.LevelName(level))
+	h.logLevel.Set(level)
+
+	return ctx.JSON(http.StatusOK, LogLevelResponse{Ok: true, Level: LogLevel(logging.LevelName(level))})
+}
+
+// logRequests assigns each request an ID, taken from its X-Request-Id header
+// when the client sent one, and logs it once handled. The request ID,
+// operation, dataset ID and trace ID are added to the request context so the
+// records logged while handling the request include them.
+func (s *Server) logRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		req := c.Request()
+
+		id := req.Header.Get(echo.HeaderXRequestID)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+		args := []any{"request_id", id}
+		if op, ok := s.operation(c); ok {
+			args = append(args, "operation", op.id)
+		}
+		if dataset := c.Param("id"); dataset != "" {
+			args = append(args, "dataset_id", dataset)
+		}
+		if span := trace.SpanContextFromContext(req.Context()); span.IsValid() {
+			args = append(args, "trace_id", span.TraceID().String())
+		}
+		ctx := logging.With(req.Context(), args...)
+		c.SetRequest(req.WithContext(ctx))
+
+		// Handle the error here so the status code is final.
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		res := c.Response()
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Int("status", res.Status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000.0),
+			slog.Int64("bytes_out", res.Size),
+			slog.String("remote_ip", c.RealIP()),
+		}
+		if rows, ok := c.Get(rowsKey).(int); ok {
+			attrs = append(attrs, slog.Int("rows", rows))
+		}
+
+		level := slog.LevelInfo
+		if res.Status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "Request", attrs...)
+
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/JayJamieson/csv-api/pkg/metrics"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newMetricsHandler returns the handler exposing the metrics of the server,
+// its database and the Go runtime.
+func newMetricsHandler(database *db.DB) (http.Handler, error) {
+	registry := prometheus.NewRegistry()
+
+	cs := append(metrics.Collectors(),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	cs = append(cs, metrics.DatabaseGauges(database.OpenDuckDBConnections, database.DataDirSize)...)
+
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
+
+// instrument counts requests and observes their duration by operation.
+func (s *Server) instrument(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		// Handle the error here so the status code is final.
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		operation := "other"
+		if op, ok := s.operation(c); ok {
+			operation = op.id
+		}
+
+		metrics.Requests.WithLabelValues(operation, c.Request().Method, strconv.Itoa(c.Response().Status)).Inc()
+		metrics.RequestDuration.WithLabelValues(operation).Observe(metrics.Since(start))
+
+		return nil
+	}
+}
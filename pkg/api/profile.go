@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+// ProfileCSV implements ServerInterface.
+func (h *Server) ProfileCSV(ctx echo.Context, id types.UUID) error {
+	profile, cached, err := h.db.GetProfile(ctx.Request().Context(), id.String())
+	if err != nil {
+		return dbErrorResponse(ctx, "Profile error", err)
+	}
+
+	columns := make([]ColumnProfile, len(profile.Columns))
+	for i, col := range profile.Columns {
+		topValues := make([]FacetValue, len(col.TopValues))
+		for j, v := range col.TopValues {
+			topValues[j] = FacetValue{Value: v.Value, Count: v.Count}
+		}
+
+		columns[i] = ColumnProfile{
+			Name:          col.Name,
+			Type:          col.Type,
+			NullCount:     col.NullCount,
+			DistinctCount: col.DistinctCount,
+			Min:           col.Min,
+			Max:           col.Max,
+			Mean:          col.Mean,
+			Stddev:        col.StdDev,
+			TopValues:     topValues,
+			SampleValues:  col.SampleValues,
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, ProfileResponse{
+		Ok:          true,
+		Cached:      cached,
+		Version:     profile.Version,
+		RowCount:    profile.RowCount,
+		GeneratedAt: profile.GeneratedAt,
+		Columns:     columns,
+	})
+}
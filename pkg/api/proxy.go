@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parseTrustedProxies parses IP addresses and CIDR ranges of proxies.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// ipExtractor returns the client IP from X-Forwarded-For behind trusted
+// proxies and the peer address otherwise.
+func ipExtractor(proxies []*net.IPNet) echo.IPExtractor {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range proxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// fromTrustedProxy reports whether the request was sent by a trusted proxy,
+// whose forwarded headers can be believed.
+func (s *Server) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	for _, proxy := range s.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// baseURL returns the scheme and host clients use to reach the server. The
+// X-Forwarded-Proto and X-Forwarded-Host headers are only used when set by a
+// trusted proxy.
+func (s *Server) baseURL(c echo.Context) string {
+	r := c.Request()
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if s.fromTrustedProxy(r) {
+		if proto := firstValue(r.Header.Get(echo.HeaderXForwardedProto)); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwarded := firstValue(r.Header.Get("X-Forwarded-Host")); forwarded != "" {
+			host = forwarded
+		}
+	}
+
+	return scheme + "://" + host
+}
+
+// datasetEndpoint returns the URL of a dataset.
+func (s *Server) datasetEndpoint(c echo.Context, id string) string {
+	return s.baseURL(c) + "/api/" + id
+}
+
+// firstValue returns the value set by the proxy closest to the client when
+// several proxies appended to a forwarded header.
+func firstValue(header string) string {
+	value, _, _ := strings.Cut(header, ",")
+	return strings.TrimSpace(value)
+}
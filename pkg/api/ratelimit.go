@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// rateLimiter keeps a token bucket for each client.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter allows limit.Rate requests per second on average, in bursts
+// of up to limit.Burst requests, or returns nil without a rate.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(limit.Rate))
+	}
+
+	return &rateLimiter{
+		rate:    limit.Rate,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// limit returns the rate and burst in effect.
+func (l *rateLimiter) limit() *RateLimit {
+	return &RateLimit{Rate: l.rate, Burst: int(l.burst)}
+}
+
+// allow takes a token from the bucket of client, or returns how long until
+// the next token is available.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled completely, at most once a
+// minute.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for client, b := range l.buckets {
+		if now.Sub(b.updated) > full {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// rateLimitClient identifies the client of a request by its API key, the
+// owner of its token, or its IP address.
+func rateLimitClient(c echo.Context) string {
+	if p := db.PrincipalFromContext(c.Request().Context()); p != nil {
+		switch {
+		case p.KeyID != "":
+			return "key:" + p.KeyID
+		case p.Owner != "":
+			return "owner:" + p.Owner
+		}
+	}
+	return "ip:" + c.RealIP()
+}
+
+// rateLimit limits the requests of each client to read operations and,
+// separately, to all other operations, which share the import limit.
+func (s *Server) rateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var limiter *rateLimiter
+		if op, ok := s.operation(c); ok {
+			limiter = s.importLimiter
+			if op.scope == scopeRead {
+				limiter = s.readLimiter
+			}
+		}
+		if limiter == nil {
+			return next(c)
+		}
+
+		if ok, wait := limiter.allow(rateLimitClient(c), time.Now()); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(seconds))
+			return errorResponse(c, http.StatusTooManyRequests, "Too many requests",
+				fmt.Sprintf("rate limit exceeded, retry after %ds", seconds))
+		}
+
+		return next(c)
+	}
+}
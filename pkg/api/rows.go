@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+// InsertRows implements ServerInterface.
+func (h *Server) InsertRows(ctx echo.Context, id types.UUID) error {
+	var body InsertRowsJSONRequestBody
+	if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	affected, err := h.db.InsertRows(ctx.Request().Context(), id.String(), body)
+	if err != nil {
+		return dbErrorResponse(ctx, "Insert error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, RowsResponse{Ok: true, Affected: affected})
+}
+
+// GetRow implements ServerInterface.
+func (h *Server) GetRow(ctx echo.Context, id types.UUID, key string, params GetRowParams) error {
+	columns, row, err := h.db.GetRow(ctx.Request().Context(), id.String(), key, string(params.Format))
+	if err != nil {
+		return dbErrorResponse(ctx, "Query error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, CSVResponse{
+		Ok:      true,
+		Total:   1,
+		Columns: columns,
+		Rows:    []any{row},
+	})
+}
+
+// UpdateRow implements ServerInterface.
+func (h *Server) UpdateRow(ctx echo.Context, id types.UUID, key string) error {
+	var body UpdateRowJSONRequestBody
+	if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	affected, err := h.db.UpdateRow(ctx.Request().Context(), id.String(), key, body)
+	if err != nil {
+		return dbErrorResponse(ctx, "Update error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, RowsResponse{Ok: true, Affected: affected})
+}
+
+// DeleteRow implements ServerInterface.
+func (h *Server) DeleteRow(ctx echo.Context, id types.UUID, key string) error {
+	affected, err := h.db.DeleteRow(ctx.Request().Context(), id.String(), key)
+	if err != nil {
+		return dbErrorResponse(ctx, "Delete error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, RowsResponse{Ok: true, Affected: affected})
+}
+
+// dbErrorResponse maps errors returned by the db package to an HTTP status.
+func dbErrorResponse(c echo.Context, error string, err error) error {
+	status := http.StatusInternalServerError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, db.ErrNotWritable):
+		status = http.StatusForbidden
+	case errors.Is(err, db.ErrInvalidInput):
+		status = http.StatusBadRequest
+	case errors.Is(err, db.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, db.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, db.ErrQuota):
+		status = http.StatusForbidden
+	case errors.Is(err, db.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.As(err, &maxBytesErr):
+		status = http.StatusRequestEntityTooLarge
+	}
+
+	return errorResponse(c, status, error, err.Error())
+}
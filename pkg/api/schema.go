@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+// GetSchema implements ServerInterface.
+func (h *Server) GetSchema(ctx echo.Context, id types.UUID) error {
+	csvTable, columns, err := h.db.GetSchema(ctx.Request().Context(), id.String())
+	if err != nil {
+		return dbErrorResponse(ctx, "Schema error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, schemaResponse(csvTable, columns))
+}
+
+// AlterSchema implements ServerInterface.
+func (h *Server) AlterSchema(ctx echo.Context, id types.UUID) error {
+	var body AlterSchemaJSONRequestBody
+	if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	changes := make([]db.SchemaChange, len(body.Changes))
+	for i, change := range body.Changes {
+		changes[i] = db.SchemaChange{Column: change.Column, Rename: change.Rename, Type: change.Type}
+	}
+
+	csvTable, columns, err := h.db.AlterSchema(ctx.Request().Context(), id.String(), changes)
+	if err != nil {
+		var castErr *db.CastError
+		if errors.As(err, &castErr) {
+			return castErrorResponse(ctx, castErr)
+		}
+		return dbErrorResponse(ctx, "Schema error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, schemaResponse(csvTable, columns))
+}
+
+func schemaResponse(csvTable *db.CSVTable, columns []db.ColumnInfo) SchemaResponse {
+	resp := SchemaResponse{Ok: true, Columns: []SchemaColumn{}}
+	for _, col := range columns {
+		if col.Name == db.RowIDColumn {
+			continue
+		}
+		resp.Columns = append(resp.Columns, SchemaColumn{
+			Name:     col.Name,
+			Type:     col.Type,
+			Nullable: !col.NotNull,
+			Key:      col.Name == csvTable.KeyColumn,
+			Label:    csvTable.ColumnLabels[col.Name],
+		})
+	}
+	return resp
+}
+
+func castErrorResponse(c echo.Context, castErr *db.CastError) error {
+	failures := make([]CastFailure, len(castErr.Failures))
+	for i, f := range castErr.Failures {
+		failures[i] = CastFailure{Column: f.Column, Type: f.Type, Count: f.Count}
+		for _, row := range f.Rows {
+			failures[i].Rows = append(failures[i].Rows, struct {
+				Rowid int64 `json:"rowid"`
+				Value any   `json:"value"`
+			}{Rowid: row.RowID, Value: row.Value})
+		}
+	}
+
+	return c.JSON(http.StatusUnprocessableEntity, CastErrorResponse{
+		Timestamp: time.Now().UTC(),
+		Error:     "Cast error",
+		Message:   castErr.Error(),
+		Failures:  failures,
+	})
+}
@@ -2,58 +2,260 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/JayJamieson/csv-api/pkg/auth"
 	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/JayJamieson/csv-api/pkg/logging"
+	"github.com/JayJamieson/csv-api/pkg/tracing"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/labstack/gommon/log"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 type Config struct {
 	Port        int
 	DatabaseURL string
+	// DataDir holds the DuckDB files of datasets, db.DefaultDataDir when
+	// empty.
+	DataDir string
+	// LogLevel is debug, info, warn, error or off, info when empty.
+	LogLevel string
+	// RequireAPIKey rejects requests without a valid API key and limits
+	// each key to the datasets of its owner.
+	RequireAPIKey bool
+	// ShareSecret signs share links. A random secret is used when empty,
+	// invalidating share links on restart.
+	ShareSecret string
+	// ShareTTL is how long share links are valid when the request does not
+	// say, 24 hours when zero. ShareMaxTTL caps the validity requested, 30
+	// days when zero.
+	ShareTTL    time.Duration
+	ShareMaxTTL time.Duration
+	// JWKS is the file path or URL of the signing keys of an identity
+	// provider. When set, JWT bearer tokens issued by JWTIssuer for
+	// JWTAudience are accepted besides API keys and authentication is
+	// required.
+	JWKS        string
+	JWTIssuer   string
+	JWTAudience string
+	// JWTOwnerClaim names the claim identifying the owner of datasets,
+	// "sub" when empty.
+	JWTOwnerClaim string
+	// ReadRateLimit limits read operations and ImportRateLimit the other
+	// operations of each API key, token owner or, without either, client
+	// IP address.
+	ReadRateLimit   RateLimit
+	ImportRateLimit RateLimit
+	// Quota limits the storage of each owner.
+	Quota db.Quota
+	// AllowedOrigins are the origins allowed to call the API from a
+	// browser, any origin when empty.
+	AllowedOrigins []string
+	// TLSCertFile and TLSKeyFile serve HTTPS instead of HTTP. The files are
+	// reloaded on SIGHUP.
+	TLSCertFile string
+	TLSKeyFile  string
+	// ReadTimeout, WriteTimeout and IdleTimeout bound the time spent reading
+	// a request, writing its response and waiting for the next request on
+	// a connection. Zero means no timeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// MaxUploadBytes limits CSV files uploaded to import and append, and
+	// MaxBodyBytes the bodies of other requests. Zero means no limit.
+	MaxUploadBytes int64
+	MaxBodyBytes   int64
+	// TrustedProxies are the IP addresses or CIDR ranges of proxies whose
+	// X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host headers are
+	// used for client IPs and dataset endpoints.
+	TrustedProxies []string
+	// OTLPEndpoint is the URL of an OpenTelemetry collector receiving
+	// traces over OTLP/HTTP, such as http://localhost:4318. Traces are not
+	// exported when empty.
+	OTLPEndpoint string
+	// TraceSampleRatio is the share of traces exported, between 0 and 1.
+	// Every trace is exported when zero.
+	TraceSampleRatio float64
+	// Metrics serves Prometheus metrics at /metrics, without authentication.
+	Metrics bool
+	// ShutdownTimeout is how long requests in flight are waited for on
+	// shutdown, 10 seconds when zero.
+	ShutdownTimeout time.Duration
 }
 
 type Server struct {
-	config Config
-	router *echo.Echo
-	db     *db.DB
+	config      Config
+	router      *echo.Echo
+	db          *db.DB
+	shareSecret []byte
+	jwt         *auth.Validator
+	// operations maps the method and route of each operation to its ID and
+	// the scope it requires.
+	operations     map[string]operation
+	readLimiter    *rateLimiter
+	importLimiter  *rateLimiter
+	trustedProxies []*net.IPNet
+	certs          *certReloader
+	// logLevel is the level of the default logger, changeable at runtime.
+	logLevel *slog.LevelVar
+	// stopTracing flushes and stops exporting traces.
+	stopTracing func(context.Context) error
 }
 
-func New(config Config) (*Server, error) {
+func New(config Config) (_ *Server, err error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	logLevel := new(slog.LevelVar)
+	level, _ := logging.ParseLevel(config.LogLevel)
+	logLevel.Set(level)
+	slog.SetDefault(logging.New(os.Stderr, logLevel))
 
-	database, err := db.New(config.DatabaseURL)
+	sampleRatio := config.TraceSampleRatio
+	if sampleRatio == 0 {
+		sampleRatio = 1
+	}
+	stopTracing, err := tracing.Start(context.Background(), config.OTLPEndpoint, sampleRatio)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if stopErr := stopTracing(context.Background()); stopErr != nil {
+				slog.Error("Error stopping tracing", "error", stopErr)
+			}
+		}
+	}()
+
+	var validator *auth.Validator
+	if config.JWKS != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		keys, err := auth.LoadKeySet(ctx, config.JWKS)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		validator = auth.NewValidator(keys, config.JWTIssuer, config.JWTAudience)
+	}
+
+	operations, err := loadOperations()
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs *certReloader
+	if config.TLSCertFile != "" {
+		certs, err = newCertReloader(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	database, err := db.New(config.DatabaseURL, config.DataDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			if closeErr := database.Close(); closeErr != nil {
+				slog.Error("Error closing database", "error", closeErr)
+			}
+		}
+	}()
 
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
 
 	server := &Server{
-		config: config,
-		router: e,
-		db:     database,
+		config:         config,
+		router:         e,
+		db:             database,
+		shareSecret:    []byte(config.ShareSecret),
+		jwt:            validator,
+		operations:     operations,
+		readLimiter:    newRateLimiter(config.ReadRateLimit),
+		importLimiter:  newRateLimiter(config.ImportRateLimit),
+		trustedProxies: trustedProxies,
+		certs:          certs,
+		logLevel:       logLevel,
+		stopTracing:    stopTracing,
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to load swagger: %w", err)
-	}
+	database.SetQuota(config.Quota)
+
+	e.IPExtractor = ipExtractor(trustedProxies)
 
-	e.Use(middleware.Logger())
+	e.Use(server.traceRequests)
+	e.Use(server.logRequests)
+	if config.Metrics {
+		e.Use(server.instrument)
+	}
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
 
-	e.Logger.SetLevel(log.INFO)
+	allowedOrigins := config.AllowedOrigins
+	if len(allowedOrigins) == 0 {
+		allowedOrigins = []string{"*"}
+	}
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:  allowedOrigins,
+		ExposeHeaders: []string{echo.HeaderRetryAfter, echo.HeaderWWWAuthenticate},
+	}))
+
+	if config.MaxUploadBytes > 0 || config.MaxBodyBytes > 0 {
+		e.Use(server.limitBody)
+	}
+
+	authenticated := config.RequireAPIKey || validator != nil
+	if authenticated {
+		e.Use(server.authenticate)
+	}
+
+	if server.readLimiter != nil || server.importLimiter != nil {
+		e.Use(server.rateLimit)
+	}
+
+	if config.ShareSecret == "" {
+		server.shareSecret = make([]byte, 32)
+		if _, err := rand.Read(server.shareSecret); err != nil {
+			return nil, fmt.Errorf("failed to generate share secret: %w", err)
+		}
+		if config.RequireAPIKey || config.JWKS != "" {
+			slog.Warn("No share secret configured, share links are invalidated on restart")
+		}
+	}
 
-	RegisterHandlers(e, server)
+	var router EchoRouter = e
+	if !authenticated {
+		router = withoutAdminRoutes{Echo: e, operations: operations}
+	}
+	RegisterHandlers(router, server)
 	server.setupDefaultRoutes()
+
+	if config.Metrics {
+		handler, err := newMetricsHandler(database)
+		if err != nil {
+			return nil, err
+		}
+		e.GET("/metrics", echo.WrapHandler(handler))
+	}
+
 	return server, nil
 }
 
@@ -65,22 +267,88 @@ func (s *Server) setupDefaultRoutes() {
 	}))
 }
 
+// limitBody rejects request bodies larger than the configured limits.
+func (s *Server) limitBody(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit := s.config.MaxBodyBytes
+		if path := c.Path(); path == "/import" || path == "/api/:id/append" {
+			limit = s.config.MaxUploadBytes
+		}
+		if limit <= 0 {
+			return next(c)
+		}
+
+		req := c.Request()
+		if req.ContentLength > limit {
+			return errorResponse(c, http.StatusRequestEntityTooLarge, "Request body too large",
+				fmt.Sprintf("request bodies are limited to %d bytes", limit))
+		}
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+
+		return next(c)
+	}
+}
+
+// reloadCertificate reloads the TLS certificate files after they were
+// renewed.
+func (s *Server) reloadCertificate() {
+	if s.certs == nil {
+		return
+	}
+
+	if err := s.certs.reload(); err != nil {
+		slog.Error("Keeping the current TLS certificate", "error", err)
+		return
+	}
+	slog.Info("Reloaded TLS certificate")
+}
+
 func (s *Server) Start() error {
+	server := s.router.Server
+	server.Addr = fmt.Sprintf(":%d", s.config.Port)
+	server.ReadTimeout = s.config.ReadTimeout
+	server.ReadHeaderTimeout = 10 * time.Second
+	server.WriteTimeout = s.config.WriteTimeout
+	server.IdleTimeout = s.config.IdleTimeout
+	if s.certs != nil {
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: s.certs.getCertificate,
+		}
+	}
+
 	go func() {
-		addr := fmt.Sprintf(":%d", s.config.Port)
-		if err := s.router.Start(addr); err != nil && err != http.ErrServerClosed {
-			s.router.Logger.Fatalf("Failed to start server: %v", err)
+		slog.Info("Server started", "addr", server.Addr, "tls", s.certs != nil)
+		if err := s.router.StartServer(server); err != nil && err != http.ErrServerClosed {
+			slog.Error("Failed to start server", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+wait:
+	for {
+		select {
+		case <-reload:
+			s.reloadCertificate()
+		case <-quit:
+			break wait
+		}
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	s.router.Logger.Info("Shutting down")
+	slog.Info("Shutting down")
 
 	if err := s.router.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown server: %w", err)
@@ -90,5 +358,9 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to close database: %w", err)
 	}
 
+	if err := s.stopTracing(ctx); err != nil {
+		return fmt.Errorf("failed to flush traces: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	defaultShareTTL    = 24 * time.Hour
+	defaultShareMaxTTL = 30 * 24 * time.Hour
+)
+
+// ShareDataset implements ServerInterface.
+func (h *Server) ShareDataset(ctx echo.Context, id types.UUID, params ShareDatasetParams) error {
+	expiry := h.config.shareTTL()
+	if params.ExpiresIn != 0 {
+		expiry = time.Duration(params.ExpiresIn) * time.Second
+	}
+	if maxExpiry := h.config.shareMaxTTL(); expiry <= 0 || expiry > maxExpiry {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid expires_in",
+			fmt.Sprintf("expires_in must be between 1 and %d seconds", int(maxExpiry.Seconds())))
+	}
+
+	csvTable, err := h.db.ShareableCSVTable(ctx.Request().Context(), id.String())
+	if err != nil {
+		return dbErrorResponse(ctx, "Share error", err)
+	}
+
+	expiresAt := time.Now().UTC().Add(expiry).Truncate(time.Second)
+	url := h.datasetEndpoint(ctx, csvTable.ID) + "?share=" + h.signShare(csvTable.ID, expiresAt.Unix())
+
+	return ctx.JSON(http.StatusOK, ShareResponse{
+		Ok:        true,
+		Url:       url,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// signShare returns the share token of dataset id, valid until the unix
+// time expires.
+func (s *Server) signShare(id string, expires int64) string {
+	mac := hmac.New(sha256.New, s.shareSecret)
+	fmt.Fprintf(mac, "%s.%d", id, expires)
+	return fmt.Sprintf("%d.%s", expires, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// verifyShare reports whether token is an unexpired share token of dataset id.
+func (s *Server) verifyShare(id, token string) bool {
+	exp, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return false
+	}
+
+	return hmac.Equal([]byte(token), []byte(s.signShare(id, expires)))
+}
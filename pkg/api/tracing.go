@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JayJamieson/csv-api/pkg/tracing"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/JayJamieson/csv-api/pkg/api")
+
+// traceRequests starts a span named by the operation of each API request,
+// continuing the trace of its W3C traceparent header when present.
+func (s *Server) traceRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		op, ok := s.operation(c)
+		if !ok {
+			return next(c)
+		}
+
+		req := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracer.Start(ctx, op.id, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("http.route", c.Path()),
+			attribute.String("url.path", req.URL.Path),
+		))
+		defer span.End()
+
+		if id := c.Param("id"); id != "" {
+			span.SetAttributes(attribute.String("dataset.id", id))
+		}
+		c.SetRequest(req.WithContext(ctx))
+
+		// Handle the error here so the status code is final.
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return nil
+	}
+}
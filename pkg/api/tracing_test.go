@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	coltrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+// collector is an OTLP/HTTP trace receiver keeping the spans it receives.
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req coltrace.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.mu.Unlock()
+
+	res, _ := proto.Marshal(&coltrace.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(res)
+}
+
+// span returns the received span named name.
+func (c *collector) span(t *testing.T, name string) *tracepb.Span {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, span := range c.spans {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	names := make([]string, len(c.spans))
+	for i, span := range c.spans {
+		names[i] = span.Name
+	}
+	t.Fatalf("no span %q, got %s", name, strings.Join(names, ", "))
+	return nil
+}
+
+func spanAttr(span *tracepb.Span, key string) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value.GetStringValue()
+		}
+	}
+	return ""
+}
+
+func TestTraceImportAndQuery(t *testing.T) {
+	received := &collector{}
+	otlp := httptest.NewServer(received)
+	defer otlp.Close()
+
+	var traceparent string
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		w.Header().Set("Content-Type", "text/csv")
+		io.WriteString(w, "id,name\n1,Otter\n2,Wolf\n")
+	}))
+	defer source.Close()
+
+	dir := t.TempDir()
+	s, err := New(Config{
+		Port:         3000,
+		DatabaseURL:  "file:" + filepath.Join(dir, "csv-api.db"),
+		DataDir:      filepath.Join(dir, "data"),
+		LogLevel:     "off",
+		OTLPEndpoint: otlp.URL,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.db.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/import?url="+source.URL+"/animals.csv", nil)
+	req.Header.Set("Traceparent", "00-"+testTraceID+"-"+testSpanID+"-01")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var imported ImportResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &imported); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	id := path.Base(imported.Endpoint)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/"+id, nil)
+	req.Header.Set("Traceparent", "00-"+testTraceID+"-"+testSpanID+"-01")
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("fetch: status %d: %s", rec.Code, rec.Body)
+	}
+
+	if err := s.stopTracing(context.Background()); err != nil {
+		t.Fatalf("stopTracing: %v", err)
+	}
+
+	spanID := func(name string) string {
+		return hex.EncodeToString(received.span(t, name).SpanId)
+	}
+
+	tests := []struct {
+		name   string
+		parent string
+	}{
+		{"ImportCSV", testSpanID},
+		{"DownloadFile", "ImportCSV"},
+		{"ImportCSVFromReader", "ImportCSV"},
+		{"writeTempCSV", "ImportCSVFromReader"},
+		{"duckdb CREATE TABLE AS", "ImportCSVFromReader"},
+		{"turso INSERT csv_table", "ImportCSVFromReader"},
+		{"FetchCSV", testSpanID},
+		{"GetCSV", "FetchCSV"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := received.span(t, tt.name)
+			if got := hex.EncodeToString(span.TraceId); got != testTraceID {
+				t.Errorf("trace ID = %s, want %s", got, testTraceID)
+			}
+
+			want := tt.parent
+			if want != testSpanID {
+				want = spanID(tt.parent)
+			}
+			if got := hex.EncodeToString(span.ParentSpanId); got != want {
+				t.Errorf("parent span ID = %s, want %s of %s", got, want, tt.parent)
+			}
+		})
+	}
+
+	if got := spanAttr(received.span(t, "GetCSV"), "db.system"); got != "duckdb" {
+		t.Errorf("GetCSV db.system = %q, want duckdb", got)
+	}
+
+	if want := "00-" + testTraceID + "-" + spanID("DownloadFile") + "-01"; traceparent != want {
+		t.Errorf("download traceparent = %q, want %q", traceparent, want)
+	}
+}
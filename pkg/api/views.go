@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JayJamieson/csv-api/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateView implements ServerInterface.
+func (h *Server) CreateView(ctx echo.Context) error {
+	var body CreateViewJSONRequestBody
+	if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	def := db.ViewDefinition{
+		Name:         body.Name,
+		Materialized: body.Materialized,
+		Datasets:     make([]db.ViewDataset, len(body.Datasets)),
+	}
+	for i, ds := range body.Datasets {
+		on := make([]db.JoinKeys, len(ds.On))
+		for j, keys := range ds.On {
+			on[j] = db.JoinKeys{Left: keys.Left, Right: keys.Right}
+		}
+		def.Datasets[i] = db.ViewDataset{
+			ID:    ds.Id.String(),
+			Alias: ds.Alias,
+			Join:  string(ds.Join),
+			On:    on,
+		}
+	}
+
+	csvTable, err := h.db.CreateView(ctx.Request().Context(), def)
+	if err != nil {
+		return dbErrorResponse(ctx, "View error", err)
+	}
+
+	endpoint := h.datasetEndpoint(ctx, csvTable.ID)
+
+	return ctx.JSON(http.StatusOK, ImportResponse{
+		Ok:       true,
+		Endpoint: endpoint,
+	})
+}
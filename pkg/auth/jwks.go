@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// keySetMaxAge is how long keys fetched from a URL are used before
+	// they are fetched again.
+	keySetMaxAge = time.Hour
+	// keySetMinRefresh limits reloads triggered by tokens signed with an
+	// unknown key and retries of failed reloads.
+	keySetMinRefresh = time.Minute
+)
+
+// jwk is a JSON Web Key as published in a JWKS document.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type publicKey struct {
+	id  string
+	alg string
+	key crypto.PublicKey
+}
+
+// KeySet holds the public keys verifying token signatures, loaded from a
+// JWKS file or URL. Keys are reloaded when a token names an unknown key and
+// periodically when loaded from a URL, so signing keys can be rotated.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    []publicKey
+	fetched time.Time
+	// attempted is when the keys were last reloaded or tried to be.
+	attempted time.Time
+}
+
+// LoadKeySet loads the JWKS at source, an http(s) URL or a file path.
+func LoadKeySet(ctx context.Context, source string) (*KeySet, error) {
+	ks := &KeySet{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	keys, err := ks.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ks.keys = keys
+	ks.fetched = time.Now()
+	ks.attempted = ks.fetched
+
+	return ks, nil
+}
+
+func (ks *KeySet) isURL() bool {
+	return strings.HasPrefix(ks.source, "http://") || strings.HasPrefix(ks.source, "https://")
+}
+
+func (ks *KeySet) fetch(ctx context.Context) ([]publicKey, error) {
+	data, err := ks.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS from %s: %w", ks.source, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS from %s: %w", ks.source, err)
+	}
+
+	return keys, nil
+}
+
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !ks.isURL() {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// key returns the key with id kid for alg. Tokens without a key ID match
+// the only key of their algorithm.
+//
+// The keys are reloaded when they are too old or have no match, by one
+// request at most every keySetMinRefresh while the others keep using the
+// current keys. The keys are kept when reloading fails, so tokens signed by
+// them stay valid while the identity provider is unreachable.
+func (ks *KeySet) key(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	key, ok := ks.find(kid, alg)
+	stale := ks.isURL() && time.Since(ks.fetched) > keySetMaxAge
+	reload := (stale || !ok) && time.Since(ks.attempted) > keySetMinRefresh
+	if reload {
+		ks.attempted = time.Now()
+	}
+	ks.mu.Unlock()
+
+	if reload {
+		// Fetch without holding the lock, a slow identity provider must not
+		// hold up requests using the current keys.
+		keys, err := ks.fetch(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "Keeping the current JWT signing keys", "error", err)
+		} else {
+			ks.mu.Lock()
+			ks.keys = keys
+			ks.fetched = time.Now()
+			key, ok = ks.find(kid, alg)
+			ks.mu.Unlock()
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s key with ID %q", ErrInvalidToken, alg, kid)
+	}
+
+	return key, nil
+}
+
+func (ks *KeySet) find(kid, alg string) (crypto.PublicKey, bool) {
+	var found crypto.PublicKey
+	matches := 0
+	for _, k := range ks.keys {
+		if k.alg != alg || (kid != "" && k.id != kid) {
+			continue
+		}
+		found = k.key
+		matches++
+	}
+
+	return found, matches == 1
+}
+
+func parseJWKS(data []byte) ([]publicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []publicKey
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, alg, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		if pub == nil || (k.Alg != "" && k.Alg != alg) {
+			// Keys for other algorithms are ignored.
+			continue
+		}
+
+		keys = append(keys, publicKey{id: k.Kid, alg: alg, key: pub})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no RS256 or ES256 signing keys")
+	}
+
+	return keys, nil
+}
+
+// publicKey decodes an RSA or P-256 key, returning a nil key for other types.
+func (k jwk) publicKey() (crypto.PublicKey, string, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, "", fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, "RS256", nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, "", nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if x.BitLen() > 256 || y.BitLen() > 256 {
+			return nil, "", fmt.Errorf("point is not on curve P-256")
+		}
+		point := append([]byte{4}, x.FillBytes(make([]byte, 32))...)
+		point = append(point, y.FillBytes(make([]byte, 32))...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, "", fmt.Errorf("point is not on curve P-256")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, "ES256", nil
+	}
+
+	return nil, "", nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// PublicJWK returns the JWK of the public half of key, as published in a
+// JWKS document.
+func PublicJWK(key crypto.Signer, kid string) (map[string]string, error) {
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported curve %s", pub.Curve.Params().Name)
+		}
+		return map[string]string{
+			"kty": "EC",
+			"kid": kid,
+			"use": "sig",
+			"alg": "ES256",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
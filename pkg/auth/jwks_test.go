@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jwksServer publishes a JWKS document, or fails while fail is set.
+type jwksServer struct {
+	mu      sync.Mutex
+	keys    []map[string]string
+	fail    bool
+	fetches int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fetches++
+	if s.fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+}
+
+func (s *jwksServer) set(fail bool, keys ...map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+	s.keys = keys
+	s.fetches = 0
+}
+
+func TestKeySetReload(t *testing.T) {
+	rsKey := loadTestKey(t, "rs256.pem")
+	esKey := loadTestKey(t, "es256.pem")
+
+	rsJWK, err := PublicJWK(rsKey, "test-rs256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	esJWK, err := PublicJWK(esKey, "test-es256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	rsToken := signTestToken(t, rsKey, "test-rs256", testClaims(now))
+	esToken := signTestToken(t, esKey, "test-es256", testClaims(now))
+
+	tests := []struct {
+		name string
+		// fetched and attempted are how long ago the keys were loaded and
+		// last reloaded or tried to be.
+		fetched   time.Duration
+		attempted time.Duration
+		// fail makes reloading fail, keys are published otherwise.
+		fail        bool
+		keys        []map[string]string
+		token       string
+		wantErr     bool
+		wantFetches int
+	}{
+		{
+			name:        "kid miss reloads",
+			fetched:     2 * keySetMinRefresh,
+			attempted:   2 * keySetMinRefresh,
+			keys:        []map[string]string{rsJWK, esJWK},
+			token:       esToken,
+			wantFetches: 1,
+		},
+		{
+			name:        "kid miss right after reload",
+			keys:        []map[string]string{rsJWK, esJWK},
+			token:       esToken,
+			wantErr:     true,
+			wantFetches: 0,
+		},
+		{
+			name:        "kid miss with failed reload",
+			fetched:     2 * keySetMinRefresh,
+			attempted:   2 * keySetMinRefresh,
+			fail:        true,
+			token:       esToken,
+			wantErr:     true,
+			wantFetches: 1,
+		},
+		{
+			name:        "kid removed from reloaded keys",
+			fetched:     2 * keySetMaxAge,
+			attempted:   2 * keySetMaxAge,
+			keys:        []map[string]string{esJWK},
+			token:       rsToken,
+			wantErr:     true,
+			wantFetches: 1,
+		},
+		{
+			name:        "current keys",
+			keys:        []map[string]string{rsJWK},
+			token:       rsToken,
+			wantFetches: 0,
+		},
+		{
+			name:        "stale keys reload",
+			fetched:     2 * keySetMaxAge,
+			attempted:   2 * keySetMaxAge,
+			keys:        []map[string]string{rsJWK},
+			token:       rsToken,
+			wantFetches: 1,
+		},
+		{
+			name:        "stale keys kept when reload fails",
+			fetched:     2 * keySetMaxAge,
+			attempted:   2 * keySetMaxAge,
+			fail:        true,
+			token:       rsToken,
+			wantFetches: 1,
+		},
+		{
+			name:        "failed reload not retried right away",
+			fetched:     2 * keySetMaxAge,
+			attempted:   keySetMinRefresh / 2,
+			fail:        true,
+			token:       rsToken,
+			wantFetches: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &jwksServer{keys: []map[string]string{rsJWK}}
+			idp := httptest.NewServer(server)
+			defer idp.Close()
+
+			keys, err := LoadKeySet(context.Background(), idp.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys.fetched = time.Now().Add(-tt.fetched)
+			keys.attempted = time.Now().Add(-tt.attempted)
+			server.set(tt.fail, tt.keys...)
+
+			validator := NewValidator(keys, testIssuer, testAudience)
+
+			// Validate twice, the second time never reloads.
+			for i := 0; i < 2; i++ {
+				_, err = validator.Validate(context.Background(), tt.token)
+				if tt.wantErr && !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Validate %d error = %v, want ErrInvalidToken", i, err)
+				}
+				if !tt.wantErr && err != nil {
+					t.Errorf("Validate %d: %v", i, err)
+				}
+			}
+
+			if server.fetches != tt.wantFetches {
+				t.Errorf("fetches = %d, want %d", server.fetches, tt.wantFetches)
+			}
+		})
+	}
+}
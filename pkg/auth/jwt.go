@@ -0,0 +1,256 @@
+// Package auth validates JWT bearer tokens issued by an OpenID Connect
+// identity provider against its published signing keys.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// leeway allows for clock skew between the server and the identity provider.
+const leeway = time.Minute
+
+// Claims are the claims of a validated token.
+type Claims map[string]any
+
+// String returns the string claim name, empty if it is missing or not a string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Scopes returns the space separated scope claim, or the scp claim used by
+// some providers.
+func (c Claims) Scopes() []string {
+	if scope, ok := c["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	switch scp := c["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []any:
+		scopes := make([]string, 0, len(scp))
+		for _, s := range scp {
+			if s, ok := s.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	}
+
+	return nil
+}
+
+// Validator checks the signature, issuer, audience and expiry of tokens.
+type Validator struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+}
+
+func NewValidator(keys *KeySet, issuer, audience string) *Validator {
+	return &Validator{keys: keys, issuer: issuer, audience: audience}
+}
+
+// Validate returns the claims of a valid RS256 or ES256 signed token.
+func (v *Validator) Validate(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	key, err := v.keys.key(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verify(key, digest[:], signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	if err := v.checkClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Validator) checkClaims(claims Claims, now time.Time) error {
+	if claims.String("iss") != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.String("iss"))
+	}
+
+	if !hasAudience(claims["aud"], v.audience) {
+		return fmt.Errorf("%w: token is not intended for audience %q", ErrInvalidToken, v.audience)
+	}
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("%w: token has no expiry", ErrInvalidToken)
+	}
+	if now.After(exp.Add(leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func hasAudience(aud any, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func numericDate(v any) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func verify(key crypto.PublicKey, digest, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// ES256 signatures are the 32 byte r and s values concatenated.
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+// Sign returns a token with claims signed by an RSA (RS256) or P-256 (ES256)
+// private key, for local testing against a JWKS holding its public key.
+func Sign(key crypto.Signer, kid string, claims map[string]any) (string, error) {
+	var alg string
+	switch k := key.Public().(type) {
+	case *rsa.PublicKey:
+		alg = "RS256"
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+		}
+		alg = "ES256"
+	default:
+		return "", fmt.Errorf("unsupported key type %T", k)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	if ecKey, ok := key.(*ecdsa.PrivateKey); ok {
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign token: %w", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	} else {
+		signature, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+		if err != nil {
+			return "", fmt.Errorf("failed to sign token: %w", err)
+		}
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ParsePrivateKey decodes a PEM encoded PKCS#8, PKCS#1 RSA or SEC 1 EC
+// private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key in %s block", block.Type)
+}
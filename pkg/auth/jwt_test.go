@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "csv-api-test"
+	testAudience = "csv-api"
+	testJWKS     = "../../testdata/jwks/jwks.json"
+)
+
+func loadTestKey(t *testing.T, name string) crypto.Signer {
+	t.Helper()
+
+	data, err := os.ReadFile("../../testdata/jwks/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePrivateKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testClaims(now time.Time) map[string]any {
+	return map[string]any{
+		"iss": testIssuer,
+		"aud": testAudience,
+		"sub": "alice",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+func signTestToken(t *testing.T, key crypto.Signer, kid string, claims map[string]any) string {
+	t.Helper()
+
+	token, err := Sign(key, kid, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestValidate(t *testing.T) {
+	rsKey := loadTestKey(t, "rs256.pem")
+	esKey := loadTestKey(t, "es256.pem")
+	now := time.Now()
+
+	with := func(changes map[string]any) map[string]any {
+		claims := testClaims(now)
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return claims
+	}
+
+	valid := signTestToken(t, rsKey, "test-rs256", testClaims(now))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{
+			name:  "RS256",
+			token: valid,
+		},
+		{
+			name:  "ES256",
+			token: signTestToken(t, esKey, "test-es256", testClaims(now)),
+		},
+		{
+			name:  "audience list",
+			token: signTestToken(t, rsKey, "test-rs256", with(map[string]any{"aud": []string{"other", testAudience}})),
+		},
+		{
+			name:  "expired within leeway",
+			token: signTestToken(t, rsKey, "test-rs256", with(map[string]any{"exp": now.Add(-leeway / 2).Unix()})),
+		},
+		{
+			name:    "bad signature",
+			token:   parts[0] + "." + encodeSegment(t, with(map[string]any{"sub": "mallory"})) + "." + parts[2],
+			wantErr: "bad signature",
+		},
+		{
+			name:    "signed by other key",
+			token:   signTestToken(t, esKey, "test-rs256", testClaims(now)),
+			wantErr: "no ES256 key",
+		},
+		{
+			name: "alg none",
+			token: encodeSegment(t, map[string]string{"alg": "none", "kid": "test-rs256"}) + "." +
+				parts[1] + ".",
+			wantErr: "unsupported algorithm",
+		},
+		{
+			name: "alg HS256",
+			token: encodeSegment(t, map[string]string{"alg": "HS256", "kid": "test-rs256"}) + "." +
+				parts[1] + "." + parts[2],
+			wantErr: "unsupported algorithm",
+		},
+		{
+			name:    "malformed",
+			token:   "not-a-token",
+			wantErr: "malformed token",
+		},
+		{
+			name:    "wrong issuer",
+			token:   signTestToken(t, rsKey, "test-rs256", with(map[string]any{"iss": "https://evil.example"})),
+			wantErr: "unexpected issuer",
+		},
+		{
+			name:    "wrong audience",
+			token:   signTestToken(t, rsKey, "test-rs256", with(map[string]any{"aud": "other"})),
+			wantErr: "not intended for audience",
+		},
+		{
+			name:    "no expiry",
+			token:   signTestToken(t, rsKey, "test-rs256", with(map[string]any{"exp": nil})),
+			wantErr: "no expiry",
+		},
+		{
+			name:    "expired",
+			token:   signTestToken(t, rsKey, "test-rs256", with(map[string]any{"exp": now.Add(-2 * leeway).Unix()})),
+			wantErr: "token expired",
+		},
+		{
+			name:    "not valid yet",
+			token:   signTestToken(t, rsKey, "test-rs256", with(map[string]any{"nbf": now.Add(2 * leeway).Unix()})),
+			wantErr: "not valid yet",
+		},
+	}
+
+	keys, err := LoadKeySet(context.Background(), testJWKS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := NewValidator(keys, testIssuer, testAudience)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := validator.Validate(context.Background(), tt.token)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				if sub := claims.String("sub"); sub != "alice" {
+					t.Errorf("sub = %q, want alice", sub)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Validate error = %v, want ErrInvalidToken", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
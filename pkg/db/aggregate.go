@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// aggregateFunctions maps the aggregate names accepted in agg to SQL.
+var aggregateFunctions = map[string]string{
+	"count":          "COUNT(%s)",
+	"count_distinct": "COUNT(DISTINCT %s)",
+	"sum":            "SUM(%s)",
+	"avg":            "AVG(%s)",
+	"min":            "MIN(%s)",
+	"max":            "MAX(%s)",
+}
+
+type AggregateQuery struct {
+	ID      string
+	GroupBy string
+	Agg     string
+	Filters []string
+	Limit   int
+	Format  string
+}
+
+// aggregateSelect parses a comma separated list of aggregates such as
+// "sum:Price,count:*,avg:Rating" into select expressions named like
+// sum_Price, count and avg_Rating.
+func aggregateSelect(agg string, columns []ColumnInfo) ([]string, []string, error) {
+	var exprs, names []string
+
+	for _, term := range splitTopLevel(agg) {
+		fn, name, ok := strings.Cut(term, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: invalid aggregate %q, expected function:column", ErrInvalidInput, term)
+		}
+
+		fn = strings.ToLower(strings.TrimSpace(fn))
+		name = strings.TrimSpace(name)
+
+		format, ok := aggregateFunctions[fn]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown aggregate function %q", ErrInvalidInput, fn)
+		}
+
+		var arg, alias string
+		if name == "*" {
+			if fn != "count" {
+				return nil, nil, fmt.Errorf("%w: only count can be applied to *", ErrInvalidInput)
+			}
+			arg, alias = "*", "count"
+		} else {
+			col, ok := findColumn(columns, name)
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: unknown aggregate column %q", ErrInvalidInput, name)
+			}
+			arg, alias = quoteIdent(col.Name), fn+"_"+col.Name
+		}
+
+		exprs = append(exprs, fmt.Sprintf(format, arg)+" AS "+quoteIdent(alias))
+		names = append(names, alias)
+	}
+
+	if len(exprs) == 0 {
+		return nil, nil, fmt.Errorf("%w: at least one aggregate is required", ErrInvalidInput)
+	}
+
+	return exprs, names, nil
+}
+
+// Aggregate groups the rows of a dataset matching the filters by the GroupBy
+// columns and computes the aggregates in Agg for each group. Groups are
+// ordered by the group columns.
+func (db *DB) Aggregate(ctx context.Context, params *AggregateQuery) (*CSVResult, error) {
+	startTime := time.Now()
+
+	csvTable, err := db.GetCSVTable(ctx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, err
+	}
+
+	tableCols, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	seen := make(map[string]bool)
+	for _, name := range splitTopLevel(params.GroupBy) {
+		col, ok := findColumn(tableCols, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown group_by column %q", ErrInvalidInput, name)
+		}
+		if seen[col.Name] {
+			return nil, fmt.Errorf("%w: duplicate group_by column %q", ErrInvalidInput, col.Name)
+		}
+		seen[col.Name] = true
+		groups = append(groups, quoteIdent(col.Name))
+	}
+
+	aggregates, names, err := aggregateSelect(params.Agg, tableCols)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidInput, name)
+		}
+		seen[name] = true
+	}
+
+	where, args, err := filterWhere(params.Filters, tableCols)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 500
+	if params.Limit > 0 {
+		limit = params.Limit
+	}
+
+	query := "SELECT " + strings.Join(append(groups, aggregates...), ", ")
+	query += " FROM " + csvTable.TableName
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if len(groups) > 0 {
+		query += " GROUP BY " + strings.Join(groups, ", ")
+		query += " ORDER BY " + strings.Join(groups, ", ")
+	}
+	query += fmt.Sprintf(" LIMIT %d", limit)
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate data: %w", err)
+	}
+	defer rows.Close()
+
+	columns, resultSet, err := scanRows(rows, params.Format, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CSVResult{
+		Columns: columns,
+		Rows:    resultSet,
+		Total:   len(resultSet),
+		QueryMs: float64(time.Since(startTime).Microseconds()) / 1000.0,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// apiKeyPrefix starts every API key, which makes leaked keys easy to find.
+const apiKeyPrefix = "csvapi_"
+
+// APIKey is a stored API key. Only a hash of the key is kept, the key itself
+// is shown once when it is created.
+type APIKey struct {
+	ID         string
+	Name       string
+	Owner      string
+	Admin      bool
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// Principal is the caller of a request. Datasets are owned by the Owner of
+// the principal that created them, and only admins see private datasets of
+// other owners. Callers without an API key have an empty Owner.
+type Principal struct {
+	Owner string
+	Admin bool
+	// KeyID is the ID of the API key authenticating the principal, empty
+	// for other principals.
+	KeyID string
+	// SharedID is the dataset opened with a valid share link, which grants
+	// read access to it while it is not private.
+	SharedID string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context scoping dataset access to p. Without a
+// principal in the context every dataset is accessible, as when
+// authentication is disabled.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal set by WithPrincipal, or nil.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// canAccess reports whether the principal of ctx may read csvTable.
+func canAccess(ctx context.Context, csvTable *CSVTable) bool {
+	if canModify(ctx, csvTable) {
+		return true
+	}
+
+	p := PrincipalFromContext(ctx)
+	switch csvTable.Visibility {
+	case VisibilityPublic:
+		return true
+	case VisibilityUnlisted:
+		return p.SharedID == csvTable.ID
+	}
+	return false
+}
+
+// canModify reports whether the principal of ctx may change csvTable.
+func canModify(ctx context.Context, csvTable *CSVTable) bool {
+	p := PrincipalFromContext(ctx)
+	return p == nil || p.Admin || (p.Owner != "" && csvTable.Owner == p.Owner)
+}
+
+// contextOwner returns the owner to record on datasets created in ctx.
+func contextOwner(ctx context.Context) sql.NullString {
+	if p := PrincipalFromContext(ctx); p != nil {
+		return sql.NullString{String: p.Owner, Valid: true}
+	}
+	return sql.NullString{}
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey mints a key for owner and returns it with the plain key,
+// which cannot be recovered later.
+func (db *DB) CreateAPIKey(ctx context.Context, owner, name string, admin bool) (*APIKey, string, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil, "", fmt.Errorf("%w: owner is required", ErrInvalidInput)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	apiKey := &APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Owner:     owner,
+		Admin:     admin,
+		CreatedAt: time.Now().UTC(),
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	_, err := db.tursoConn.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, key_hash, owner, admin, created_at) VALUES (?, ?, ?, ?, ?, ?)
+	`, apiKey.ID, apiKey.Name, hashAPIKey(key), apiKey.Owner, apiKey.Admin, apiKey.CreatedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return apiKey, key, nil
+}
+
+// RevokeAPIKey disables a key. Revoked keys are kept for auditing.
+func (db *DB) RevokeAPIKey(ctx context.Context, id string) error {
+	res, err := db.tursoConn.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("active API key %s %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns all keys, including revoked ones, oldest first.
+func (db *DB) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := db.tursoConn.QueryContext(ctx, `
+		SELECT id, name, owner, admin, created_at, last_used_at, revoked_at
+		FROM api_keys ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var k APIKey
+		var lastUsed, revoked sql.NullTime
+		if err := rows.Scan(&k.ID, &k.Name, &k.Owner, &k.Admin, &k.CreatedAt, &lastUsed, &revoked); err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		if lastUsed.Valid {
+			k.LastUsedAt = &lastUsed.Time
+		}
+		if revoked.Valid {
+			k.RevokedAt = &revoked.Time
+		}
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Authenticate returns the principal of an active API key.
+func (db *DB) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, fmt.Errorf("%w: malformed API key", ErrUnauthorized)
+	}
+
+	var p Principal
+	err := db.tursoConn.QueryRowContext(ctx,
+		"SELECT id, owner, admin FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL",
+		hashAPIKey(key)).Scan(&p.KeyID, &p.Owner, &p.Admin)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: invalid or revoked API key", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	if _, err := db.tursoConn.ExecContext(ctx,
+		"UPDATE api_keys SET last_used_at = ? WHERE id = ?", time.Now().UTC(), p.KeyID); err != nil {
+		slog.ErrorContext(ctx, "Error updating last use of API key", "key_id", p.KeyID, "error", err)
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,561 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+const (
+	MissingColumnsNull  = "null"
+	MissingColumnsError = "error"
+
+	ExtraColumnsError  = "error"
+	ExtraColumnsIgnore = "ignore"
+	ExtraColumnsAdd    = "add"
+
+	AppendModeAppend = "append"
+	AppendModeMerge  = "merge"
+)
+
+// AppendOptions controls how the header of an appended CSV file is matched
+// against the columns of an existing dataset.
+type AppendOptions struct {
+	// MissingColumns is MissingColumnsNull or MissingColumnsError.
+	MissingColumns string
+	// ExtraColumns is ExtraColumnsError, ExtraColumnsIgnore or ExtraColumnsAdd.
+	ExtraColumns string
+	// Strict disables widening of column types to fit appended values.
+	Strict bool
+	// Mode is AppendModeAppend or AppendModeMerge. In merge mode rows whose
+	// KeyColumns match an existing row update it instead of being appended.
+	Mode       string
+	KeyColumns []string
+	// DeleteMissing removes rows whose key is absent from the file in merge mode.
+	DeleteMissing bool
+}
+
+type AppendResult struct {
+	Inserted int
+	Updated  int
+	Deleted  int
+}
+
+var integerTypeRank = map[string]int{
+	"TINYINT":  1,
+	"SMALLINT": 2,
+	"INTEGER":  3,
+	"BIGINT":   4,
+	"HUGEINT":  5,
+}
+
+func isFloatType(t string) bool {
+	return t == "FLOAT" || t == "DOUBLE" || strings.HasPrefix(t, "DECIMAL")
+}
+
+// widenType returns the narrowest DuckDB type holding values of both target
+// and source, falling back to VARCHAR.
+func widenType(target, source string) string {
+	target = strings.ToUpper(target)
+	source = strings.ToUpper(source)
+
+	if target == source || source == "NULL" || source == "SQLNULL" {
+		return target
+	}
+
+	targetRank, targetInt := integerTypeRank[target]
+	sourceRank, sourceInt := integerTypeRank[source]
+
+	switch {
+	case targetInt && sourceInt:
+		if sourceRank > targetRank {
+			return source
+		}
+		return target
+	case (targetInt || isFloatType(target)) && (sourceInt || isFloatType(source)):
+		return "DOUBLE"
+	case target == "TIMESTAMP" && source == "DATE", target == "DATE" && source == "TIMESTAMP":
+		return "TIMESTAMP"
+	}
+
+	return "VARCHAR"
+}
+
+// matchColumns compares the header of the appended file with the dataset
+// columns and returns the source columns to insert and the columns to add to
+// the dataset.
+func matchColumns(target, source []ColumnInfo, opts AppendOptions) ([]ColumnInfo, []ColumnInfo, error) {
+	// Appended rows get new row ids from the dataset, row ids in the file
+	// (e.g. from an export of the dataset) are ignored.
+	isRowID := func(col ColumnInfo) bool { return col.Name == RowIDColumn }
+	target = slices.DeleteFunc(slices.Clone(target), isRowID)
+	source = slices.DeleteFunc(slices.Clone(source), isRowID)
+
+	if opts.MissingColumns == MissingColumnsError {
+		var missing []string
+		for _, col := range target {
+			if _, ok := findColumn(source, col.Name); !ok {
+				missing = append(missing, col.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, nil, fmt.Errorf("%w: CSV is missing columns %s", ErrInvalidInput, strings.Join(missing, ", "))
+		}
+	}
+
+	var insert, add []ColumnInfo
+	var extra []string
+
+	for _, col := range source {
+		if _, ok := findColumn(target, col.Name); ok {
+			insert = append(insert, col)
+			continue
+		}
+
+		switch opts.ExtraColumns {
+		case ExtraColumnsIgnore:
+		case ExtraColumnsAdd:
+			insert = append(insert, col)
+			add = append(add, col)
+		default:
+			extra = append(extra, col.Name)
+		}
+	}
+
+	if len(extra) > 0 {
+		return nil, nil, fmt.Errorf("%w: CSV has columns not in dataset: %s", ErrInvalidInput, strings.Join(extra, ", "))
+	}
+
+	if len(insert) == 0 {
+		return nil, nil, fmt.Errorf("%w: CSV has no columns in common with dataset", ErrInvalidInput)
+	}
+
+	if opts.Mode == AppendModeMerge {
+		if len(opts.KeyColumns) == 0 {
+			return nil, nil, fmt.Errorf("%w: merge requires at least one key column", ErrInvalidInput)
+		}
+		for _, key := range opts.KeyColumns {
+			_, inTarget := findColumn(target, key)
+			_, inSource := findColumn(source, key)
+			if !inTarget || !inSource {
+				return nil, nil, fmt.Errorf("%w: key column %q must be in both the dataset and the CSV", ErrInvalidInput, key)
+			}
+		}
+	}
+
+	return insert, add, nil
+}
+
+func columnList(columns []ColumnInfo) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col.Name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func readCSVQuery(path string) string {
+	return fmt.Sprintf("SELECT * FROM read_csv_auto('%s', auto_detect=TRUE, strict_mode=false, store_rejects=true)", path)
+}
+
+// AppendCSVFromReader appends the rows of a CSV file to an existing dataset
+// in a single transaction. The file is staged in DuckDB and its header matched
+// by name against the dataset columns according to opts.
+func (db *DB) AppendCSVFromReader(ctx context.Context, id string, reader io.Reader, opts AppendOptions) (*AppendResult, error) {
+	csvTable, err := db.ownedCSVTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if csvTable.View != nil {
+		return nil, fmt.Errorf("%w: rows cannot be appended to a view", ErrInvalidInput)
+	}
+
+	if err := db.checkQuota(ctx, 0); err != nil {
+		return nil, err
+	}
+
+	result, err := db.appendCSV(ctx, csvTable, reader, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (db *DB) appendCSV(ctx context.Context, csvTable *CSVTable, reader io.Reader, opts AppendOptions) (*AppendResult, error) {
+	id := csvTable.ID
+
+	if csvTable.ColumnLabels != nil {
+		opts.KeyColumns = resolveLabels(csvTable.ColumnLabels, opts.KeyColumns)
+	}
+
+	tempFile, _, cleanup, err := writeTempCSV(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if csvTable.Persisted {
+		return db.appendToTurso(ctx, csvTable, tempFile, opts)
+	}
+
+	duckConn, err := db.getDuckDBConnection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Temporary tables are scoped to a connection, so pin one for the
+	// staging table and the transaction using it.
+	conn, err := duckConn.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DuckDB connection: %w", err)
+	}
+	defer conn.Close()
+
+	csvSource, labels, err := readCSVSource(ctx, conn, tempFile, csvTable.ColumnLabels != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "CREATE OR REPLACE TEMP TABLE staging AS "+csvSource); err != nil {
+		return nil, fmt.Errorf("failed to read CSV into DuckDB: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "DROP TABLE IF EXISTS staging")
+
+	target, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := tableColumns(ctx, conn, "staging")
+	if err != nil {
+		return nil, err
+	}
+
+	insert, add, err := matchColumns(target, source, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var alterations []string
+
+	for _, col := range add {
+		alterations = append(alterations, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+			csvTable.TableName, quoteIdent(col.Name), col.Type))
+	}
+
+	if !opts.Strict {
+		for _, col := range insert {
+			targetCol, ok := findColumn(target, col.Name)
+			if !ok {
+				continue
+			}
+
+			widened := widenType(targetCol.Type, col.Type)
+			if widened == strings.ToUpper(targetCol.Type) {
+				continue
+			}
+
+			alterations = append(alterations, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s",
+				csvTable.TableName, quoteIdent(col.Name), widened))
+		}
+	}
+
+	// DuckDB cannot alter a table with indexes, and an index dropped in a
+	// transaction cannot be recreated in the same transaction. Drop them up
+	// front and recreate them in the transaction, or restore them on failure.
+	if len(alterations) > 0 {
+		if err = dropIndexes(ctx, conn, csvTable, csvTable.TableName); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				if idxErr := createIndexes(context.Background(), conn, csvTable, csvTable.TableName); idxErr != nil {
+					slog.ErrorContext(ctx, "Error restoring indexes", "error", idxErr)
+				}
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	for _, stmt := range alterations {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to alter dataset columns: %w", err)
+		}
+	}
+
+	result, err := applyStaged(ctx, tx, csvTable.TableName, insert, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(alterations) > 0 {
+		if err = createIndexes(ctx, tx, csvTable, csvTable.TableName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = db.datasetChanged(ctx, tx, csvTable); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	db.addColumnLabels(ctx, csvTable, labels, add)
+
+	return result, nil
+}
+
+// applyStaged copies rows from the staging table into tableName within tx.
+// In merge mode rows are matched on opts.KeyColumns: matches update the
+// existing row, other rows are inserted and, with DeleteMissing, existing
+// rows without a match are deleted.
+func applyStaged(ctx context.Context, tx *sql.Tx, tableName string, insert []ColumnInfo, opts AppendOptions) (*AppendResult, error) {
+	cols := columnList(insert)
+
+	if opts.Mode != AppendModeMerge {
+		res, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM staging",
+			tableName, cols, cols))
+		if err != nil {
+			return nil, keyConflict(fmt.Errorf("failed to append rows: %w", err))
+		}
+
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		return &AppendResult{Inserted: int(inserted)}, nil
+	}
+
+	keys := make([]string, len(opts.KeyColumns))
+	match := make([]string, len(opts.KeyColumns))
+	for i, key := range opts.KeyColumns {
+		keys[i] = quoteIdent(key)
+		match[i] = fmt.Sprintf("%s.%s = staging.%s", tableName, keys[i], keys[i])
+	}
+	matchCond := strings.Join(match, " AND ")
+
+	// Rows without a key match no row, merging them would insert them again
+	// on every merge.
+	nullKeys := make([]string, len(keys))
+	keyText := make([]string, len(keys))
+	for i, key := range keys {
+		nullKeys[i] = key + " IS NULL"
+		keyText[i] = fmt.Sprintf("CAST(%s AS VARCHAR)", key)
+	}
+
+	var missing int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM staging WHERE "+strings.Join(nullKeys, " OR ")).Scan(&missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for null keys: %w", err)
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("%w: key %s is null in %d CSV row(s), merged rows need a key",
+			ErrInvalidInput, strings.Join(opts.KeyColumns, ", "), missing)
+	}
+
+	var duplicate string
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT %s FROM staging GROUP BY %s HAVING COUNT(*) > 1 LIMIT 1",
+		strings.Join(keyText, " || ',' || "), strings.Join(keys, ", "))).Scan(&duplicate)
+	if err == nil {
+		return nil, fmt.Errorf("%w: key %q appears more than once in CSV", ErrInvalidInput, duplicate)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to check for duplicate keys: %w", err)
+	}
+
+	result := &AppendResult{}
+
+	var assignments []string
+	for _, col := range insert {
+		if slices.Contains(opts.KeyColumns, col.Name) {
+			continue
+		}
+		assignments = append(assignments, fmt.Sprintf("%s = staging.%s", quoteIdent(col.Name), quoteIdent(col.Name)))
+	}
+
+	if len(assignments) > 0 {
+		res, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s FROM staging WHERE %s",
+			tableName, strings.Join(assignments, ", "), matchCond))
+		if err != nil {
+			return nil, keyConflict(fmt.Errorf("failed to update rows: %w", err))
+		}
+		if result.Updated, err = affected(res); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM staging WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s)",
+		tableName, cols, cols, tableName, matchCond))
+	if err != nil {
+		return nil, keyConflict(fmt.Errorf("failed to insert rows: %w", err))
+	}
+	if result.Inserted, err = affected(res); err != nil {
+		return nil, err
+	}
+
+	if opts.DeleteMissing {
+		res, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"DELETE FROM %s WHERE NOT EXISTS (SELECT 1 FROM staging WHERE %s)",
+			tableName, matchCond))
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete rows: %w", err)
+		}
+		if result.Deleted, err = affected(res); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func affected(res sql.Result) (int, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return int(n), nil
+}
+
+// appendToTurso stages the CSV file in an in-memory DuckDB database and
+// applies its rows to a persisted dataset.
+func (db *DB) appendToTurso(ctx context.Context, csvTable *CSVTable, tempFile string, opts AppendOptions) (*AppendResult, error) {
+	staging, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB connection: %w", err)
+	}
+	defer staging.Close()
+
+	csvSource, labels, err := readCSVSource(ctx, staging, tempFile, csvTable.ColumnLabels != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := staging.ExecContext(ctx, "CREATE TABLE staging AS "+csvSource); err != nil {
+		return nil, fmt.Errorf("failed to read CSV into DuckDB: %w", err)
+	}
+
+	target, err := tableColumns(ctx, db.tursoConn, csvTable.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := tableColumns(ctx, staging, "staging")
+	if err != nil {
+		return nil, err
+	}
+
+	insert, add, err := matchColumns(target, source, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := columnList(insert)
+	dataRows, err := staging.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM staging", cols))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged rows: %w", err)
+	}
+	defer dataRows.Close()
+
+	tx, err := db.tursoConn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	for _, col := range add {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+			csvTable.TableName, quoteIdent(col.Name), persistedType(col.Type)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to add column %q: %w", col.Name, err)
+		}
+	}
+
+	// Copy the staged rows into a temporary table of the Turso connection so
+	// the same set based statements as for DuckDB apply the append or merge.
+	stagingCols := make([]string, len(insert))
+	for i, col := range insert {
+		stagingCols[i] = quoteIdent(col.Name) + " " + persistedType(col.Type)
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("CREATE TEMP TABLE staging (%s)", strings.Join(stagingCols, ", ")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	insertStmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO staging (%s) VALUES (%s)",
+		cols, strings.TrimSuffix(strings.Repeat("?, ", len(insert)), ", ")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	defer insertStmt.Close()
+
+	for dataRows.Next() {
+		values := make([]any, len(insert))
+		scanArgs := make([]any, len(insert))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+
+		if err = dataRows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("failed to scan staged row: %w", err)
+		}
+
+		if _, err = insertStmt.ExecContext(ctx, persistedValues(insert, values)...); err != nil {
+			return nil, fmt.Errorf("failed to insert data: %w", err)
+		}
+	}
+
+	if err = dataRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating staged rows: %w", err)
+	}
+
+	result, err := applyStaged(ctx, tx, csvTable.TableName, insert, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DROP TABLE temp.staging"); err != nil {
+		return nil, fmt.Errorf("failed to drop staging table: %w", err)
+	}
+
+	if err = db.datasetChanged(ctx, tx, csvTable); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	db.addColumnLabels(ctx, csvTable, labels, add)
+
+	return result, nil
+}
@@ -4,6 +4,10 @@ import (
 	"time"
 )
 
+// RowIDColumn is the persisted row identifier added to every imported table.
+// It is assigned in file order at import and increases for appended rows.
+const RowIDColumn = "_rowid"
+
 type transformFunc func(columns []string, values []any) any
 
 var transformFuncs = map[string]transformFunc{
@@ -17,6 +21,49 @@ type CSVTable struct {
 	TableName string    `json:"table_name" db:"table_name"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	Persisted bool      `json:"persisted" db:"persisted"`
+	Writable  bool      `json:"writable" db:"writable"`
+	// KeyColumn is the unique key used to look up single rows, empty if
+	// rows are addressed by row id.
+	KeyColumn      string   `json:"key_column" db:"key_column"`
+	IndexedColumns []string `json:"indexed_columns" db:"indexed_columns"`
+	// SuggestedFacets are columns with few distinct values, recomputed
+	// whenever the rows or columns of the dataset change.
+	SuggestedFacets []string `json:"suggested_facets" db:"suggested_facets"`
+	// SearchColumns are the columns in the full-text index, empty if the
+	// dataset cannot be searched.
+	SearchColumns []string `json:"search_columns" db:"search_columns"`
+	// Version increases whenever the rows of the dataset change.
+	Version int64 `json:"version" db:"version"`
+	// ColumnLabels maps each column to its header in the imported file when
+	// headers were normalized, nil otherwise.
+	ColumnLabels map[string]string `json:"column_labels" db:"column_labels"`
+	// View defines how a view joins other datasets, nil for datasets
+	// imported from a file.
+	View *ViewDefinition `json:"view" db:"view_definition"`
+	// Owner is the owner of the API key that created the dataset, empty
+	// when it was created without authentication.
+	Owner string `json:"owner" db:"owner"`
+	// Visibility is one of VisibilityPrivate, VisibilityUnlisted or
+	// VisibilityPublic.
+	Visibility string `json:"visibility" db:"visibility"`
+}
+
+// ImportOptions controls how a CSV file is imported into a new dataset.
+type ImportOptions struct {
+	// Writable allows rows to be inserted, updated and deleted after import.
+	Writable bool
+	// KeyColumn is validated to be unique and not null and gets a unique index.
+	KeyColumn string
+	// IndexedColumns get a non-unique index each.
+	IndexedColumns []string
+	// SearchColumns are indexed for full-text search, all text columns
+	// when empty.
+	SearchColumns []string
+	// NormalizeHeaders renames columns to snake_case ASCII names, keeping
+	// the headers as written as column labels.
+	NormalizeHeaders bool
+	// Visibility defaults to VisibilityPrivate.
+	Visibility string
 }
 
 type ColumnInfo struct {
@@ -35,7 +82,19 @@ type QueryCSV struct {
 	Offset     int
 	SortColumn string
 	SortOrder  string
+	Sort       string
 	Format     string
+	HideRowID  bool
+	Cursor     string
+	Columns    string
+	Exclude    string
+	Filters    []string
+	Facets     []string
+	FacetSize  int
+	Search     string
+	// OriginalLabels names the result columns after the headers of the
+	// imported file instead of the column names.
+	OriginalLabels bool
 }
 
 func transformArray(columns []string, values []any) any {
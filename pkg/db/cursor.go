@@ -0,0 +1,140 @@
+package db
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func sortSignature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// pageCursor is the opaque position after the last row of a page: the sort
+// key values of that row and the sort they belong to.
+type pageCursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func encodeCursor(keys []sortKey, columns []ColumnInfo, values []any) (string, error) {
+	c := pageCursor{
+		Sort:   sortSignature(keys),
+		Values: make([]any, len(values)),
+	}
+
+	for i, v := range values {
+		col, _ := findColumn(columns, keys[i].Column)
+		c.Values[i] = cursorValue(col, v)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// cursorValue converts a scanned value to a JSON value that compares equal
+// to it when bound as a query parameter again.
+func cursorValue(col ColumnInfo, v any) any {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case time.Time:
+		if strings.EqualFold(col.Type, "DATE") {
+			return val.Format(time.DateOnly)
+		}
+		return val.Format("2006-01-02 15:04:05.999999999")
+	}
+	return v
+}
+
+func decodeCursor(s string, keys []sortKey) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var c pageCursor
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+
+	if c.Sort != sortSignature(keys) || len(c.Values) != len(keys) {
+		return nil, fmt.Errorf("%w: cursor does not match the requested sort", ErrInvalidInput)
+	}
+
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			if n64, err := n.Int64(); err == nil {
+				c.Values[i] = n64
+			} else if f, err := n.Float64(); err == nil {
+				c.Values[i] = f
+			}
+		}
+	}
+
+	return c.Values, nil
+}
+
+// keysetCondition returns a condition selecting the rows ordered after the
+// row with the given sort key values, honouring direction and null placement
+// of each key:
+//
+//	after(k1) OR (k1 = v1 AND (after(k2) OR (k2 = v2 AND ...)))
+func keysetCondition(keys []sortKey, values []any) (string, []any) {
+	if len(keys) == 0 {
+		return "FALSE", nil
+	}
+
+	k, v := keys[0], values[0]
+	col := quoteIdent(k.Column)
+
+	var after string
+	var args []any
+
+	if v == nil {
+		if k.NullsFirst {
+			after = col + " IS NOT NULL"
+		} else {
+			after = "FALSE"
+		}
+	} else {
+		op := ">"
+		if k.Desc {
+			op = "<"
+		}
+		after = fmt.Sprintf("%s %s ?", col, op)
+		args = append(args, v)
+		if !k.NullsFirst {
+			after = fmt.Sprintf("(%s OR %s IS NULL)", after, col)
+		}
+	}
+
+	if len(keys) == 1 {
+		return after, args
+	}
+
+	equal := col + " IS NULL"
+	if v != nil {
+		equal = col + " = ?"
+		args = append(args, v)
+	}
+
+	rest, restArgs := keysetCondition(keys[1:], values[1:])
+	args = append(args, restArgs...)
+
+	return fmt.Sprintf("(%s OR (%s AND (%s)))", after, equal, rest), args
+}
@@ -3,27 +3,66 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"math"
+	"math/big"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/JayJamieson/csv-api/pkg/logging"
+	"github.com/JayJamieson/csv-api/pkg/metrics"
+	"github.com/JayJamieson/csv-api/pkg/tracing"
 	"github.com/google/uuid"
-	_ "github.com/marcboeker/go-duckdb/v2"
+	"github.com/marcboeker/go-duckdb/v2"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrNotWritable  = errors.New("dataset is not writable")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrQuota        = errors.New("quota exceeded")
+	ErrUnavailable  = errors.New("unavailable")
+)
+
+var tracer = tracing.Tracer("github.com/JayJamieson/csv-api/pkg/db")
+
+// DefaultDataDir is the directory holding the DuckDB files of datasets when
+// none is configured.
+const DefaultDataDir = "./data"
+
 type DB struct {
 	tursoConn *sql.DB
-	duckDBMap map[string]*sql.DB
 	dataDir   string
+	quota     Quota
+
+	// ftsErr is why DuckDB's full-text search extension could not be
+	// installed, nil when it is available.
+	ftsErr error
+
+	// mu guards duckDBMap, views and searchIndexes.
+	mu            sync.Mutex
+	duckDBMap     map[string]*sql.DB
+	views         map[string]*virtualView
+	searchIndexes map[string]*searchIndex
 }
 
-func New(dbURL string) (*DB, error) {
+// Open connects to the metadata database without applying migrations.
+func Open(dbURL string) (*DB, error) {
 	conn, err := sql.Open("libsql", dbURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -31,35 +70,57 @@ func New(dbURL string) (*DB, error) {
 
 	conn.SetConnMaxIdleTime(9)
 
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS csv_table (
-			id TEXT PRIMARY KEY,
-			filename TEXT NOT NULL,
-			table_name TEXT NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			persisted BOOLEAN DEFAULT 0
-		)
-	`)
+	return &DB{
+		tursoConn:     conn,
+		duckDBMap:     make(map[string]*sql.DB),
+		dataDir:       DefaultDataDir,
+		views:         make(map[string]*virtualView),
+		searchIndexes: make(map[string]*searchIndex),
+	}, nil
+}
+
+// New connects to the metadata database, applies pending migrations and
+// prepares the data directory, DefaultDataDir when dataDir is empty.
+func New(dbURL, dataDir string) (*DB, error) {
+	db, err := Open(dbURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create csv_table: %w", err)
+		return nil, err
+	}
+
+	if dataDir != "" {
+		db.dataDir = dataDir
 	}
 
-	dataDir := "./data"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
+	applied, err := db.Migrate(context.Background())
+	if err != nil {
+		db.tursoConn.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	for _, m := range applied {
+		slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+	}
+
+	if err := os.MkdirAll(db.dataDir, 0755); err != nil {
+		db.tursoConn.Close()
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	return &DB{
-		tursoConn: conn,
-		duckDBMap: make(map[string]*sql.DB),
-		dataDir:   dataDir,
-	}, nil
+	if err := installFTS(context.Background()); err != nil {
+		db.ftsErr = fmt.Errorf("%w: full-text search needs DuckDB's fts extension, which could not be installed: %w", ErrUnavailable, err)
+		slog.Warn("Full-text search of ephemeral datasets is unavailable", "error", err)
+	}
+
+	return db, nil
 }
 
 func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
 	for _, duckConn := range db.duckDBMap {
 		if err := duckConn.Close(); err != nil {
-			log.Printf("Error closing DuckDB connection: %v", err)
+			slog.Error("Error closing DuckDB connection", "error", err)
 		}
 	}
 
@@ -71,6 +132,9 @@ func (db *DB) getDuckDBPath(id string) string {
 }
 
 func (db *DB) getDuckDBConnection(id string) (*sql.DB, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
 	if conn, ok := db.duckDBMap[id]; ok {
 		return conn, nil
 	}
@@ -81,241 +145,733 @@ func (db *DB) getDuckDBConnection(id string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to open DuckDB connection: %w", err)
 	}
 
+	if err := upgradeRowIDColumn(context.Background(), conn, "csv_data"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
 	db.duckDBMap[id] = conn
 	return conn, nil
 }
 
-func (db *DB) ImportCSVFromReader(ctx context.Context, filename string, reader io.Reader) (*CSVTable, error) {
-	id := uuid.New().String()
-	tableName := "csv_data"
+// removeDuckDB closes the DuckDB connection of a dataset and deletes its file.
+func (db *DB) removeDuckDB(id string) {
+	db.mu.Lock()
+	conn, ok := db.duckDBMap[id]
+	delete(db.duckDBMap, id)
+	delete(db.views, id)
+	delete(db.searchIndexes, id)
+	db.mu.Unlock()
+
+	if ok {
+		if err := conn.Close(); err != nil {
+			slog.Error("Error closing DuckDB connection", "error", err)
+		}
+	}
+
+	dbPath := db.getDuckDBPath(id)
+	for _, path := range []string{dbPath, dbPath + ".wal"} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			slog.Error("Error removing DuckDB file", "path", path, "error", err)
+		}
+	}
+}
+
+// writeTempCSV copies reader to a temporary file for DuckDB's read_csv_auto
+// and returns its path and size. The returned cleanup function removes the
+// file.
+func writeTempCSV(ctx context.Context, reader io.Reader) (_ string, _ int64, _ func(), err error) {
+	_, span := tracer.Start(ctx, "writeTempCSV")
+	defer func() { tracing.End(span, err) }()
 
 	tempDir, err := os.MkdirTemp("", "csv-import")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		return "", 0, nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
-	defer os.RemoveAll(tempDir)
+	cleanup := func() { os.RemoveAll(tempDir) }
 
 	tempFile := filepath.Join(tempDir, "data.csv")
 	f, err := os.Create(tempFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		cleanup()
+		return "", 0, nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
 
-	if _, err := io.Copy(f, reader); err != nil {
+	size, err := io.Copy(f, reader)
+	span.SetAttributes(attribute.Int64("csv.bytes", size))
+	if err != nil {
 		f.Close()
-		return nil, fmt.Errorf("failed to write CSV data: %w", err)
+		cleanup()
+		return "", 0, nil, fmt.Errorf("failed to write CSV data: %w", err)
 	}
 	f.Close()
 
+	return tempFile, size, cleanup, nil
+}
+
+func (db *DB) ImportCSVFromReader(ctx context.Context, filename string, reader io.Reader, opts ImportOptions) (*CSVTable, error) {
+	ctx, span := tracer.Start(ctx, "ImportCSVFromReader")
+	start := time.Now()
+	csvTable, err := db.importCSV(ctx, filename, reader, opts)
+	tracing.End(span, err)
+
+	status := metrics.Status(err)
+	metrics.Imports.WithLabelValues(status).Inc()
+	metrics.ImportDuration.WithLabelValues(status).Observe(metrics.Since(start))
+
+	return csvTable, err
+}
+
+func (db *DB) importCSV(ctx context.Context, filename string, reader io.Reader, opts ImportOptions) (*CSVTable, error) {
+	visibility, err := parseVisibility(opts.Visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.checkQuota(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	tableName := "csv_data"
+	ctx = logging.With(ctx, "dataset_id", id)
+
+	tempFile, size, cleanup, err := writeTempCSV(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
 	duckConn, err := db.getDuckDBConnection(id)
 	if err != nil {
 		return nil, err
 	}
 
-	query := fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM read_csv_auto('%s', auto_detect=TRUE, strict_mode=false, store_rejects=true)",
-		tableName, tempFile)
+	// Remove the DuckDB file again if the import fails after creating it.
+	defer func() {
+		if err != nil {
+			db.removeDuckDB(id)
+		}
+	}()
+
+	// Temporary tables are scoped to a connection, so pin one for the
+	// staging table and the table created from it.
+	conn, err := duckConn.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DuckDB connection: %w", err)
+	}
+	defer conn.Close()
+
+	source, labels, err := readCSVSource(ctx, conn, tempFile, opts.NormalizeHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	// A window without ORDER BY numbers rows in no particular order. The CSV
+	// is staged first, which keeps its order in the staging table's rowid,
+	// and row ids are numbered by it.
+	if _, err = createTableAs(ctx, conn, "CREATE OR REPLACE TEMP TABLE staging AS "+source); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(context.Background(), "DROP TABLE IF EXISTS staging")
+
+	selected, err := stagedColumns(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("CREATE TABLE %s AS SELECT row_number() OVER (ORDER BY rowid) AS %s, %s FROM staging ORDER BY %s",
+		tableName, RowIDColumn, selected, RowIDColumn)
+
+	rows, err := createTableAs(ctx, conn, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = createRowIDSequence(ctx, duckConn, tableName); err != nil {
+		return nil, err
+	}
+
+	// Columns can be referred to by their original headers.
+	if labels != nil {
+		opts.KeyColumn = resolveLabel(labels, opts.KeyColumn)
+		opts.IndexedColumns = resolveLabels(labels, opts.IndexedColumns)
+		opts.SearchColumns = resolveLabels(labels, opts.SearchColumns)
+	}
+
+	csvTable := &CSVTable{
+		ID:             id,
+		Filename:       filename,
+		TableName:      tableName,
+		CreatedAt:      time.Now().UTC(),
+		Persisted:      false,
+		Version:        1,
+		Writable:       opts.Writable,
+		KeyColumn:      opts.KeyColumn,
+		IndexedColumns: opts.IndexedColumns,
+		ColumnLabels:   labels,
+		Owner:          contextOwner(ctx).String,
+		Visibility:     visibility,
+	}
+
+	if err = validateIndexes(ctx, duckConn, csvTable); err != nil {
+		return nil, err
+	}
+
+	if err = createIndexes(ctx, duckConn, csvTable, tableName); err != nil {
+		return nil, err
+	}
+
+	var indexedColumns []byte
+	if len(csvTable.IndexedColumns) > 0 {
+		indexedColumns, _ = json.Marshal(csvTable.IndexedColumns)
+	}
+
+	importedCols, err := tableColumns(ctx, duckConn, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	searchColumns, err := searchableColumns(importedCols, opts.SearchColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(searchColumns) > 0 {
+		ftsErr := db.createSearchIndex(ctx, duckConn, tableName, searchColumns)
+		switch {
+		case errors.Is(ftsErr, ErrUnavailable):
+			// The columns are kept, so the dataset is searchable once
+			// persisted, which indexes them with SQLite's FTS5.
+			slog.WarnContext(ctx, "Search columns are not indexed until the dataset is persisted",
+				"search_columns", searchColumns, "error", ftsErr)
+		case ftsErr != nil:
+			// Search is optional unless columns were asked for explicitly.
+			if len(opts.SearchColumns) > 0 {
+				err = ftsErr
+				return nil, err
+			}
+			slog.ErrorContext(ctx, "Error creating search index", "error", ftsErr)
+			searchColumns = nil
+		default:
+			db.searchIndexBuilt(id, csvTable.Version)
+		}
+	}
+	csvTable.SearchColumns = searchColumns
+
+	var searchColumnsJSON []byte
+	if len(searchColumns) > 0 {
+		searchColumnsJSON, _ = json.Marshal(searchColumns)
+	}
+
+	suggested, suggestErr := suggestFacets(ctx, duckConn, tableName)
+	if suggestErr != nil {
+		slog.ErrorContext(ctx, "Error suggesting facets", "error", suggestErr)
+	}
+	csvTable.SuggestedFacets = suggested
+	suggestedFacets, _ := json.Marshal(suggested)
 
-	if _, err := duckConn.Exec(query); err != nil {
-		return nil, fmt.Errorf("failed to import CSV into DuckDB: %w", err)
+	var columnLabels []byte
+	if labels != nil {
+		columnLabels, _ = json.Marshal(labels)
 	}
 
-	now := time.Now().UTC()
-	_, err = db.tursoConn.ExecContext(ctx, `
-		INSERT INTO csv_table (id, filename, table_name, created_at, persisted)
-		VALUES (?, ?, ?, ?, 0)
-	`, id, filename, tableName, now)
+	if err = db.checkStorageQuota(ctx, id); err != nil {
+		return nil, err
+	}
+
+	insertCtx, span := tracer.Start(ctx, "turso INSERT csv_table", trace.WithAttributes(attribute.String("db.system", "turso")))
+	_, err = db.tursoConn.ExecContext(insertCtx, `
+		INSERT INTO csv_table (id, filename, table_name, created_at, persisted, writable, key_column, indexed_columns, suggested_facets, search_columns, column_labels, owner, visibility)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, filename, tableName, csvTable.CreatedAt, opts.Writable,
+		sql.NullString{String: opts.KeyColumn, Valid: opts.KeyColumn != ""},
+		sql.NullString{String: string(indexedColumns), Valid: indexedColumns != nil},
+		sql.NullString{String: string(suggestedFacets), Valid: suggested != nil},
+		sql.NullString{String: string(searchColumnsJSON), Valid: searchColumnsJSON != nil},
+		sql.NullString{String: string(columnLabels), Valid: columnLabels != nil},
+		contextOwner(ctx), visibility)
+	tracing.End(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store CSV reference: %w", err)
 	}
 
-	return &CSVTable{
-		ID:        id,
-		Filename:  filename,
-		TableName: tableName,
-		CreatedAt: now,
-		Persisted: false,
-	}, nil
+	metrics.ImportBytes.Add(float64(size))
+	metrics.ImportRows.Add(float64(rows))
+	slog.InfoContext(ctx, "Imported CSV", "filename", filename, "rows", rows, "bytes", size)
+
+	return csvTable, nil
 }
 
-func (db *DB) GetCSVTable(ctx context.Context, id string) (*CSVTable, error) {
+// stagedColumns returns the select list of the staging table's columns. A
+// CSV column named rowid hides DuckDB's rowid, so it is renamed in staging
+// and selected under its name.
+func stagedColumns(ctx context.Context, conn *sql.Conn) (string, error) {
+	columns, err := tableColumns(ctx, conn, "staging")
+	if err != nil {
+		return "", err
+	}
+
+	selected := make([]string, len(columns))
+	for i, col := range columns {
+		selected[i] = quoteIdent(col.Name)
+		if !strings.EqualFold(col.Name, "rowid") {
+			continue
+		}
+
+		staged := "_csv_rowid"
+		for slices.ContainsFunc(columns, func(c ColumnInfo) bool { return strings.EqualFold(c.Name, staged) }) {
+			staged = "_" + staged
+		}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE staging RENAME COLUMN %s TO %s",
+			quoteIdent(col.Name), quoteIdent(staged))); err != nil {
+			return "", fmt.Errorf("failed to rename CSV column %q: %w", col.Name, err)
+		}
+		selected[i] = quoteIdent(staged) + " AS " + quoteIdent(col.Name)
+	}
+
+	return strings.Join(selected, ", "), nil
+}
+
+// createTableAs runs a CREATE TABLE AS query on DuckDB and returns the
+// number of rows created.
+func createTableAs(ctx context.Context, conn execer, query string) (rows int64, err error) {
+	ctx, span := tracer.Start(ctx, "duckdb CREATE TABLE AS", trace.WithAttributes(
+		attribute.String("db.system", "duckdb"), attribute.String("db.statement", query)))
+	defer func() { tracing.End(span, err) }()
+
+	result, err := conn.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to import CSV into DuckDB: %w", err)
+	}
+
+	rows, _ = result.RowsAffected()
+	span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	return rows, nil
+}
+
+const csvTableColumns = "id, filename, table_name, created_at, persisted, writable, key_column, indexed_columns, suggested_facets, search_columns, version, column_labels, view_definition, owner, visibility"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCSVTable(row rowScanner) (*CSVTable, error) {
 	var csvTable CSVTable
-	err := db.tursoConn.QueryRowContext(ctx, `
-		SELECT id, filename, table_name, created_at, persisted
-		FROM csv_table
-		WHERE id = ?
-	`, id).Scan(&csvTable.ID, &csvTable.Filename, &csvTable.TableName, &csvTable.CreatedAt, &csvTable.Persisted)
+	var keyColumn, indexedColumns, suggestedFacets, searchColumns, columnLabels, viewDefinition, owner sql.NullString
+
+	err := row.Scan(&csvTable.ID, &csvTable.Filename, &csvTable.TableName, &csvTable.CreatedAt,
+		&csvTable.Persisted, &csvTable.Writable, &keyColumn, &indexedColumns, &suggestedFacets, &searchColumns, &csvTable.Version, &columnLabels, &viewDefinition, &owner, &csvTable.Visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	csvTable.KeyColumn = keyColumn.String
+	csvTable.Owner = owner.String
+	if indexedColumns.String != "" {
+		if err := json.Unmarshal([]byte(indexedColumns.String), &csvTable.IndexedColumns); err != nil {
+			return nil, fmt.Errorf("invalid indexed_columns: %w", err)
+		}
+	}
+	if suggestedFacets.String != "" {
+		if err := json.Unmarshal([]byte(suggestedFacets.String), &csvTable.SuggestedFacets); err != nil {
+			return nil, fmt.Errorf("invalid suggested_facets: %w", err)
+		}
+	}
+	if searchColumns.String != "" {
+		if err := json.Unmarshal([]byte(searchColumns.String), &csvTable.SearchColumns); err != nil {
+			return nil, fmt.Errorf("invalid search_columns: %w", err)
+		}
+	}
+	if columnLabels.String != "" {
+		if err := json.Unmarshal([]byte(columnLabels.String), &csvTable.ColumnLabels); err != nil {
+			return nil, fmt.Errorf("invalid column_labels: %w", err)
+		}
+	}
+	if viewDefinition.String != "" {
+		if err := json.Unmarshal([]byte(viewDefinition.String), &csvTable.View); err != nil {
+			return nil, fmt.Errorf("invalid view_definition: %w", err)
+		}
+	}
+
+	return &csvTable, nil
+}
+
+func (db *DB) GetCSVTable(ctx context.Context, id string) (*CSVTable, error) {
+	csvTable, err := scanCSVTable(db.tursoConn.QueryRowContext(ctx,
+		"SELECT "+csvTableColumns+" FROM csv_table WHERE id = ?", id))
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("CSV table with ID %s not found", id)
+			return nil, fmt.Errorf("CSV table with ID %s %w", id, ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get CSV table: %w", err)
 	}
-	return &csvTable, nil
+
+	// Datasets the caller cannot read are reported as missing so their IDs
+	// are not confirmed to exist.
+	if !canAccess(ctx, csvTable) {
+		return nil, fmt.Errorf("CSV table with ID %s %w", id, ErrNotFound)
+	}
+
+	return csvTable, nil
 }
 
-func (db *DB) GetCSV(ctx context.Context, params *QueryCSV) ([]string, []any, int, float64, error) {
+// ListCSVTables returns the datasets accessible in ctx, newest first.
+func (db *DB) ListCSVTables(ctx context.Context) ([]*CSVTable, error) {
+	query := "SELECT " + csvTableColumns + " FROM csv_table"
+	var args []any
+	if p := PrincipalFromContext(ctx); p != nil && !p.Admin {
+		query += " WHERE owner = ?"
+		args = append(args, p.Owner)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := db.tursoConn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CSV tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []*CSVTable
+	for rows.Next() {
+		csvTable, err := scanCSVTable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan CSV table: %w", err)
+		}
+		tables = append(tables, csvTable)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating CSV tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// CSVResult is a page of rows returned by GetCSV. Next is the cursor for the
+// following page and empty on the last page.
+type CSVResult struct {
+	Columns         []string
+	Rows            []any
+	Total           int
+	QueryMs         float64
+	Next            string
+	Facets          []Facet
+	SuggestedFacets []string
+	// Snippets holds the highlighted matches of a search for each row.
+	Snippets []map[string]string
+}
+
+func (db *DB) GetCSV(ctx context.Context, params *QueryCSV) (*CSVResult, error) {
+	ctx, span := tracer.Start(ctx, "GetCSV", trace.WithAttributes(attribute.String("dataset.id", params.ID)))
+	result, err := db.getCSV(ctx, params)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (db *DB) getCSV(ctx context.Context, params *QueryCSV) (*CSVResult, error) {
 	startTime := time.Now()
 
-	duckConn, err := db.getDuckDBConnection(params.ID)
+	csvTable, err := db.GetCSVTable(ctx, params.ID)
 	if err != nil {
-		return nil, nil, 0, 0, err
+		return nil, err
 	}
 
-	sortOrder := "ASC"
-	sortColumn := "rowid"
-	limit := 500
+	backend := "duckdb"
+	if csvTable.Persisted {
+		backend = "turso"
+	}
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("db.system", backend))
 
-	if params.SortOrder != "ASC" {
-		sortOrder = params.SortOrder
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, err
 	}
 
-	if params.SortColumn != "" {
-		sortColumn = params.SortColumn
+	tableCols, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, err
 	}
 
+	limit := 500
 	if params.Limit > 0 {
 		limit = params.Limit
 	}
-	query := "SELECT row_number() OVER () as _id, "
-	query += "* FROM " + params.TableName
-	query += fmt.Sprintf(" ORDER BY \"%s\"%s", sortColumn, sortOrder)
-	query += fmt.Sprintf(" LIMIT %d", limit)
 
-	if params.Offset > 0 {
-		query += fmt.Sprintf(" OFFSET %d", params.Offset)
-	}
+	// Searches read from a subquery of the matching rows, which adds the
+	// relevance score as a column that can be selected and sorted by.
+	source := csvTable.TableName
+	var args []any
+	var terms []string
 
-	rows, err := duckConn.Query(query)
+	if params.Search != "" {
+		source, args, err = db.searchSource(ctx, conn, csvTable, params.Search)
+		if err != nil {
+			return nil, err
+		}
+		terms = searchTerms(params.Search)
+		tableCols = append(tableCols, ColumnInfo{Name: ScoreColumn, Type: "DOUBLE"})
+	}
 
+	keys, err := querySortKeys(params, tableCols)
 	if err != nil {
-		return nil, nil, 0, 0, fmt.Errorf("failed to query data: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	columns, err := rows.Columns()
+	selected, err := projection(params.Columns, params.Exclude, tableCols, params.HideRowID)
 	if err != nil {
-		return nil, nil, 0, 0, fmt.Errorf("failed to get columns: %w", err)
+		return nil, err
 	}
 
-	var resultSet []any
+	// The sort key values of each row are selected after the output columns
+	// so the cursor can be built from the last row of the page.
+	query := "SELECT " + strings.Join(selected, ", ")
+	for i, k := range keys {
+		query += fmt.Sprintf(", %s AS __k%d", quoteIdent(k.Column), i)
+	}
+	if terms != nil {
+		for i, col := range csvTable.SearchColumns {
+			query += fmt.Sprintf(", %s AS __s%d", quoteIdent(col), i)
+		}
+	}
+	query += " FROM " + source
 
-	for rows.Next() {
-		values := make([]any, len(columns))
+	where, filterArgs, err := filterWhere(params.Filters, tableCols)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, filterArgs...)
 
-		for i := range values {
-			values[i] = &values[i]
+	var facets []Facet
+	if len(params.Facets) > 0 {
+		facets, err = facetCounts(ctx, conn, source, params.Facets, params.FacetSize, tableCols, where, args)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		if err := rows.Scan(values...); err != nil {
-			return nil, nil, 0, 0, fmt.Errorf("failed to scan row: %w", err)
+	var conditions []string
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+
+	if params.Cursor != "" {
+		if params.Offset > 0 {
+			return nil, fmt.Errorf("%w: cursor cannot be combined with offset", ErrInvalidInput)
 		}
 
-		transformResult := transformFuncs[params.Format](columns, values)
+		after, err := decodeCursor(params.Cursor, keys)
+		if err != nil {
+			return nil, err
+		}
 
-		resultSet = append(resultSet, transformResult)
+		keyset, keysetArgs := keysetCondition(keys, after)
+		conditions = append(conditions, keyset)
+		args = append(args, keysetArgs...)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, nil, 0, 0, fmt.Errorf("error iterating rows: %w", err)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	queryTime := float64(time.Since(startTime).Microseconds()) / 1000.0
+	query += " ORDER BY " + orderByClause(keys)
+	query += fmt.Sprintf(" LIMIT %d", limit)
+
+	if params.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", params.Offset)
+	}
 
-	return columns, resultSet, len(resultSet), queryTime, nil
-}
+	span.SetAttributes(attribute.String("db.statement", query))
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data: %w", err)
+	}
+	defer rows.Close()
 
-func (db *DB) QueryCSVTable(
-	ctx context.Context,
-	tableName string,
-	limit int,
-	offset int,
-	sortCol string,
-	sortDesc bool,
-	showRowID bool,
-) ([]string, []map[string]any, [][]any, int, float64, error) {
-	startTime := time.Now()
+	trailing := len(keys)
+	if terms != nil {
+		trailing += len(csvTable.SearchColumns)
+	}
 
-	query := "SELECT "
-	if showRowID {
-		query += "rowid, "
+	var labels map[string]string
+	if params.OriginalLabels {
+		labels = csvTable.ColumnLabels
 	}
-	query += "* FROM " + tableName
 
-	if sortCol != "" {
-		direction := ""
-		if sortDesc {
-			direction = " DESC"
+	var last []any
+	var snippets []map[string]string
+
+	columns, resultSet, err := scanRows(rows, params.Format, trailing, labels, func(extra []any) {
+		last = extra[:len(keys)]
+		if terms != nil {
+			snippets = append(snippets, rowSnippets(csvTable.SearchColumns, extra[len(keys):], terms))
 		}
-		query += fmt.Sprintf(" ORDER BY \"%s\"%s", sortCol, direction)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
-	}
-	if offset > 0 {
-		query += fmt.Sprintf(" OFFSET %d", offset)
+	result := &CSVResult{
+		Columns:         columns,
+		Rows:            resultSet,
+		Total:           len(resultSet),
+		Facets:          facets,
+		SuggestedFacets: csvTable.SuggestedFacets,
+		Snippets:        snippets,
 	}
 
-	rows, err := db.tursoConn.QueryContext(ctx, query)
-	if err != nil {
-		return nil, nil, nil, 0, 0, fmt.Errorf("failed to query data: %w", err)
+	if len(resultSet) == limit {
+		result.Next, err = encodeCursor(keys, tableCols, last)
+		if err != nil {
+			return nil, err
+		}
 	}
-	defer rows.Close()
 
+	result.QueryMs = float64(time.Since(startTime).Microseconds()) / 1000.0
+
+	span.SetAttributes(attribute.Int("db.rows", result.Total))
+	metrics.QueryDuration.WithLabelValues(backend).Observe(result.QueryMs / 1000)
+	slog.DebugContext(ctx, "Queried rows", "backend", backend, "rows", result.Total, "duration_ms", result.QueryMs)
+
+	return result, nil
+}
+
+// scanRows reads all rows and transforms them to the requested format. The
+// last trailing columns are not part of the output, their values are passed
+// to each for every row when it is not nil. Columns found in labels are
+// renamed to their label.
+func scanRows(rows *sql.Rows, format string, trailing int, labels map[string]string, each func(extra []any)) ([]string, []any, error) {
 	columns, err := rows.Columns()
 	if err != nil {
-		return nil, nil, nil, 0, 0, fmt.Errorf("failed to get columns: %w", err)
+		return nil, nil, fmt.Errorf("failed to get columns: %w", err)
 	}
+	columns = columns[:len(columns)-trailing]
 
-	var objectRows []map[string]any
-	var arrayRows [][]any
+	for i, col := range columns {
+		if label, ok := labels[col]; ok {
+			columns[i] = label
+		}
+	}
+
+	transform, ok := transformFuncs[format]
+	if !ok {
+		transform = transformObject
+	}
+
+	var resultSet []any
 
 	for rows.Next() {
-		values := make([]any, len(columns))
+		values := make([]any, len(columns)+trailing)
 
-		scanArgs := make([]any, len(columns))
 		for i := range values {
-			scanArgs[i] = &values[i]
+			values[i] = &values[i]
 		}
 
-		if err := rows.Scan(scanArgs...); err != nil {
-			return nil, nil, nil, 0, 0, fmt.Errorf("failed to scan row: %w", err)
+		if err := rows.Scan(values...); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		objRow := make(map[string]any)
-		arrRow := make([]any, len(columns))
+		transformResult := transform(columns, values[:len(columns)])
 
-		for i, col := range columns {
-			val := values[i]
-			if b, ok := val.([]byte); ok {
-				val = string(b)
-			}
-			objRow[col] = val
-			arrRow[i] = val
+		resultSet = append(resultSet, transformResult)
+		if each != nil {
+			each(values[len(columns):])
 		}
-
-		objectRows = append(objectRows, objRow)
-		arrayRows = append(arrayRows, arrRow)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, nil, nil, 0, 0, fmt.Errorf("error iterating rows: %w", err)
+		return nil, nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	queryTime := float64(time.Since(startTime).Microseconds()) / 1000.0
+	return columns, resultSet, nil
+}
+
+// persistedTypes are the DuckDB column types persisted tables declare as
+// they are. SQLite gives such columns numeric or text affinity from the type
+// name, so values compare as numbers, dates or text like in DuckDB, and rows
+// written later are converted by coerceValue the same way on both backends.
+// Columns of other types are TEXT.
+var persistedTypes = regexp.MustCompile(`^(BOOLEAN|TINYINT|SMALLINT|INTEGER|BIGINT|HUGEINT|` +
+	`UTINYINT|USMALLINT|UINTEGER|UBIGINT|UHUGEINT|FLOAT|REAL|DOUBLE|DECIMAL\(\d{1,2}, ?\d{1,2}\)|` +
+	`VARCHAR|DATE|TIME|TIMESTAMP|TIMESTAMP WITH TIME ZONE)$`)
+
+// persistedType returns the type of a persisted table column holding values
+// of a DuckDB column type.
+func persistedType(duckType string) string {
+	if persistedTypes.MatchString(duckType) {
+		return duckType
+	}
+	return "TEXT"
+}
 
-	return columns, objectRows, arrayRows, len(arrayRows), queryTime, nil
+// persistedValues converts values scanned from DuckDB columns for the
+// columns of a persisted table. Dates and times are written as ISO 8601 text
+// in the form the rows API accepts and cursors encode, which sorts in time
+// order, timestamps in UTC.
+func persistedValues(columns []ColumnInfo, values []any) []any {
+	converted := make([]any, len(values))
+	for i, v := range values {
+		colType := strings.ToUpper(columns[i].Type)
+
+		switch val := v.(type) {
+		case nil, bool, string, int8, int16, int32, int64, uint8, uint16, uint32, float32, float64:
+			converted[i] = val
+		case uint64:
+			if val > math.MaxInt64 {
+				// Stored as REAL by the column's numeric affinity.
+				converted[i] = strconv.FormatUint(val, 10)
+			} else {
+				converted[i] = int64(val)
+			}
+		case *big.Int:
+			converted[i] = val.String()
+		case duckdb.Decimal:
+			converted[i] = val.Float64()
+		case time.Time:
+			switch colType {
+			case "DATE":
+				converted[i] = val.Format(time.DateOnly)
+			case "TIME":
+				converted[i] = val.Format("15:04:05.999999")
+			default:
+				converted[i] = val.UTC().Format("2006-01-02 15:04:05.999999")
+			}
+		case []byte:
+			if colType == "UUID" && len(val) == 16 {
+				converted[i] = uuid.UUID(val).String()
+			} else {
+				converted[i] = string(val)
+			}
+		default:
+			converted[i] = fmt.Sprintf("%v", val)
+		}
+	}
+	return converted
 }
 
 func (db *DB) PersistToTurso(ctx context.Context, id string) error {
-	csvTable, err := db.GetCSVTable(ctx, id)
+	ctx, span := tracer.Start(ctx, "PersistToTurso", trace.WithAttributes(attribute.String("db.system", "turso")))
+	start := time.Now()
+	err := db.persistToTurso(ctx, id)
+	tracing.End(span, err)
+	metrics.PersistDuration.WithLabelValues(metrics.Status(err)).Observe(metrics.Since(start))
+	return err
+}
+
+func (db *DB) persistToTurso(ctx context.Context, id string) error {
+	csvTable, err := db.ownedCSVTable(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	if csvTable.Persisted {
-		return fmt.Errorf("table already persisted")
+		return fmt.Errorf("%w: table already persisted", ErrConflict)
+	}
+
+	if csvTable.isVirtual() {
+		return fmt.Errorf("%w: virtual views cannot be persisted", ErrInvalidInput)
 	}
 
 	duckConn, err := db.getDuckDBConnection(id)
@@ -323,25 +879,9 @@ func (db *DB) PersistToTurso(ctx context.Context, id string) error {
 		return err
 	}
 
-	columnQuery := fmt.Sprintf("PRAGMA table_info('%s')", csvTable.TableName)
-	rows, err := duckConn.Query(columnQuery)
+	columns, err := tableColumns(ctx, duckConn, csvTable.TableName)
 	if err != nil {
-		return fmt.Errorf("failed to get table info: %w", err)
-	}
-
-	var columns []ColumnInfo
-	for rows.Next() {
-		var col ColumnInfo
-		if err := rows.Scan(&col.CID, &col.Name, &col.Type, &col.NotNull, &col.DefaultVal, &col.PK); err != nil {
-			rows.Close()
-			return fmt.Errorf("failed to scan column info: %w", err)
-		}
-		columns = append(columns, col)
-	}
-	rows.Close()
-
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("error iterating column rows: %w", err)
+		return err
 	}
 
 	tx, err := db.tursoConn.BeginTx(ctx, nil)
@@ -351,7 +891,7 @@ func (db *DB) PersistToTurso(ctx context.Context, id string) error {
 	defer func() {
 		if err != nil {
 			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Printf("Error rolling back transaction: %v", rbErr)
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
 			}
 		}
 	}()
@@ -359,11 +899,21 @@ func (db *DB) PersistToTurso(ctx context.Context, id string) error {
 	tursoPermanentTableName := "csv_" + strings.ReplaceAll(id, "-", "_")
 	createTableSQL := fmt.Sprintf("CREATE TABLE %s (", tursoPermanentTableName)
 
-	createTableSQL += fmt.Sprintf("\"%s\" TEXT", strings.ReplaceAll(columns[0].Name, "\"", ""))
+	for i, col := range columns {
+		if i > 0 {
+			createTableSQL += ", "
+		}
+
+		// The row id column aliases SQLite's rowid so persisted rows keep
+		// their identifiers, AUTOINCREMENT stops ids of deleted rows being
+		// reused.
+		if col.Name == RowIDColumn {
+			createTableSQL += RowIDColumn + " INTEGER PRIMARY KEY AUTOINCREMENT"
+			continue
+		}
 
-	for _, col := range columns[1:] {
 		sanitizedCol := strings.ReplaceAll(col.Name, "\"", "")
-		createTableSQL += fmt.Sprintf(", \"%s\" TEXT", sanitizedCol)
+		createTableSQL += fmt.Sprintf("\"%s\" %s", sanitizedCol, persistedType(col.Type))
 	}
 	createTableSQL += ")"
 
@@ -372,6 +922,10 @@ func (db *DB) PersistToTurso(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to create permanent table: %w", err)
 	}
 
+	if err = createIndexes(ctx, tx, csvTable, tursoPermanentTableName); err != nil {
+		return err
+	}
+
 	dataQuery := fmt.Sprintf("SELECT * FROM %s", csvTable.TableName)
 	dataRows, err := duckConn.Query(dataQuery)
 	if err != nil {
@@ -417,22 +971,7 @@ func (db *DB) PersistToTurso(ctx context.Context, id string) error {
 			return fmt.Errorf("failed to scan data row: %w", err)
 		}
 
-		stringValues := make([]any, len(values))
-		for i, v := range values {
-			if v == nil {
-				stringValues[i] = nil
-				continue
-			}
-
-			switch val := v.(type) {
-			case []byte:
-				stringValues[i] = string(val)
-			default:
-				stringValues[i] = fmt.Sprintf("%v", val)
-			}
-		}
-
-		if _, err := insertStmt.ExecContext(ctx, stringValues...); err != nil {
+		if _, err := insertStmt.ExecContext(ctx, persistedValues(columns, values)...); err != nil {
 			return fmt.Errorf("failed to insert data: %w", err)
 		}
 	}
@@ -441,9 +980,19 @@ func (db *DB) PersistToTurso(ctx context.Context, id string) error {
 		return fmt.Errorf("error iterating data rows: %w", err)
 	}
 
+	if err = continueRowIDSequence(ctx, duckConn, tx, csvTable.TableName, tursoPermanentTableName); err != nil {
+		return err
+	}
+
+	if len(csvTable.SearchColumns) > 0 {
+		if err = createTursoSearchIndex(ctx, tx, tursoPermanentTableName, csvTable.SearchColumns); err != nil {
+			return err
+		}
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		UPDATE csv_table
-		SET persisted = 1, table_name = ?
+		SET persisted = 1, table_name = ?, version = version + 1
 		WHERE id = ?
 	`, tursoPermanentTableName, id)
 	if err != nil {
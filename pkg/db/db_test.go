@@ -0,0 +1,315 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+const testCSV = `Name,Price,Sold
+Apple,5,2024-01-15
+Banana,120,2024-03-02
+Cherry,99.5,2023-12-31
+Date,1000,2024-02-29
+`
+
+// newTestDB opens a DB with a SQLite metadata database and a data directory
+// in a temporary directory.
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := New("file:"+filepath.Join(dir, "csv-api.db"), filepath.Join(dir, "data"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// importTestCSV imports data as a dataset and returns its ID.
+func importTestCSV(t *testing.T, db *DB, data string, opts ImportOptions) string {
+	t.Helper()
+
+	csvTable, err := db.ImportCSVFromReader(context.Background(), "test.csv", strings.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("ImportCSVFromReader: %v", err)
+	}
+
+	return csvTable.ID
+}
+
+// queryColumn returns the values of column for the rows of a query.
+func queryColumn(t *testing.T, db *DB, params QueryCSV, column string) []any {
+	t.Helper()
+
+	params.Format = "objects"
+	result, err := db.GetCSV(context.Background(), &params)
+	if err != nil {
+		t.Fatalf("GetCSV: %v", err)
+	}
+
+	values := make([]any, len(result.Rows))
+	for i, row := range result.Rows {
+		values[i] = row.(map[string]any)[column]
+	}
+
+	return values
+}
+
+func TestPersistKeepsColumnTypes(t *testing.T) {
+	db := newTestDB(t)
+	id := importTestCSV(t, db, testCSV, ImportOptions{})
+
+	if err := db.PersistToTurso(context.Background(), id); err != nil {
+		t.Fatalf("PersistToTurso: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params QueryCSV
+		column string
+		want   []string
+	}{
+		{
+			name:   "sort numbers descending",
+			params: QueryCSV{ID: id, Sort: "-Price"},
+			column: "Name",
+			want:   []string{"Date", "Banana", "Cherry", "Apple"},
+		},
+		{
+			name:   "filter numbers",
+			params: QueryCSV{ID: id, Sort: "Price", Filters: []string{"Price:gt:100"}},
+			column: "Name",
+			want:   []string{"Banana", "Date"},
+		},
+		{
+			name:   "sort dates",
+			params: QueryCSV{ID: id, Sort: "Sold"},
+			column: "Name",
+			want:   []string{"Cherry", "Apple", "Date", "Banana"},
+		},
+		{
+			name:   "filter dates",
+			params: QueryCSV{ID: id, Sort: "Sold", Filters: []string{"Sold:gte:2024-02-01"}},
+			column: "Name",
+			want:   []string{"Date", "Banana"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, db, tt.params, tt.column)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPersistMaterializedView(t *testing.T) {
+	db := newTestDB(t)
+	products := importTestCSV(t, db, testCSV, ImportOptions{})
+	orders := importTestCSV(t, db, "Product,Quantity\nBanana,3\nApple,1\nBanana,2\n", ImportOptions{})
+
+	view, err := db.CreateView(context.Background(), ViewDefinition{
+		Name:         "orders",
+		Materialized: true,
+		Datasets: []ViewDataset{
+			{ID: orders, Alias: "orders"},
+			{ID: products, Alias: "products", On: []JoinKeys{{Left: "Product", Right: "Name"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateView: %v", err)
+	}
+
+	if err := db.PersistToTurso(context.Background(), view.ID); err != nil {
+		t.Fatalf("PersistToTurso: %v", err)
+	}
+
+	got := queryColumn(t, db, QueryCSV{ID: view.ID, Sort: "_rowid"}, "Price")
+	want := []any{120.0, 5.0, 120.0}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestVirtualViewSeesSourceChanges(t *testing.T) {
+	db := newTestDB(t)
+	products := importTestCSV(t, db, testCSV, ImportOptions{})
+	orders := importTestCSV(t, db, "Product,Quantity\nBanana,3\nApple,1\n", ImportOptions{})
+
+	// products is joined twice to check that a dataset can be used more
+	// than once.
+	view, err := db.CreateView(context.Background(), ViewDefinition{
+		Name: "orders",
+		Datasets: []ViewDataset{
+			{ID: orders, Alias: "orders"},
+			{ID: products, Alias: "products", On: []JoinKeys{{Left: "Product", Right: "Name"}}},
+			{ID: products, Alias: "again", On: []JoinKeys{{Left: "products.Name", Right: "Name"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateView: %v", err)
+	}
+
+	got := queryColumn(t, db, QueryCSV{ID: view.ID, Sort: "_rowid"}, "again_Price")
+	if len(got) != 2 || got[0] != 120.0 || got[1] != 5.0 {
+		t.Fatalf("got %v, want [120 5]", got)
+	}
+
+	// The added column leaves a schema change in the write-ahead log of
+	// the dataset.
+	_, err = db.AppendCSVFromReader(context.Background(), orders, strings.NewReader("Product,Quantity,Note\nDate,7,gift\n"),
+		AppendOptions{ExtraColumns: ExtraColumnsAdd})
+	if err != nil {
+		t.Fatalf("AppendCSVFromReader: %v", err)
+	}
+
+	got = queryColumn(t, db, QueryCSV{ID: view.ID, Sort: "_rowid"}, "Name")
+	if len(got) != 3 || got[2] != "Date" {
+		t.Fatalf("got %v, want [Banana Apple Date]", got)
+	}
+}
+
+func TestAlterSchemaRenamesMetadata(t *testing.T) {
+	for _, persisted := range []bool{false, true} {
+		db := newTestDB(t)
+		id := importTestCSV(t, db, testCSV, ImportOptions{KeyColumn: "Name", IndexedColumns: []string{"Price"}})
+		if persisted {
+			if err := db.PersistToTurso(context.Background(), id); err != nil {
+				t.Fatalf("PersistToTurso: %v", err)
+			}
+		}
+
+		_, _, err := db.AlterSchema(context.Background(), id, []SchemaChange{
+			{Column: "Name", Rename: "product"},
+			{Column: "Price", Rename: "price"},
+		})
+		if err != nil {
+			t.Fatalf("AlterSchema (persisted %v): %v", persisted, err)
+		}
+
+		csvTable, err := db.GetCSVTable(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetCSVTable: %v", err)
+		}
+		if csvTable.KeyColumn != "product" || len(csvTable.IndexedColumns) != 1 || csvTable.IndexedColumns[0] != "price" {
+			t.Fatalf("persisted %v: key %q, indexed %v, want product and [price]",
+				persisted, csvTable.KeyColumn, csvTable.IndexedColumns)
+		}
+	}
+}
+
+func TestSearchColumnsIndexedOnPersist(t *testing.T) {
+	db := newTestDB(t)
+	id := importTestCSV(t, db, testCSV, ImportOptions{SearchColumns: []string{"Name"}})
+
+	csvTable, err := db.GetCSVTable(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetCSVTable: %v", err)
+	}
+	if len(csvTable.SearchColumns) != 1 || csvTable.SearchColumns[0] != "Name" {
+		t.Fatalf("search columns %v, want [Name]", csvTable.SearchColumns)
+	}
+
+	if db.ftsErr != nil {
+		_, err := db.GetCSV(context.Background(), &QueryCSV{ID: id, Search: "banana"})
+		if !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("search without fts extension: got %v, want ErrUnavailable", err)
+		}
+	}
+
+	if err := db.PersistToTurso(context.Background(), id); err != nil {
+		t.Fatalf("PersistToTurso: %v", err)
+	}
+
+	got := queryColumn(t, db, QueryCSV{ID: id, Search: "banana"}, "Name")
+	if len(got) != 1 || got[0] != "Banana" {
+		t.Fatalf("got %v, want [Banana]", got)
+	}
+}
+
+func TestCursorPaginationAcrossPersist(t *testing.T) {
+	db := newTestDB(t)
+	id := importTestCSV(t, db, testCSV, ImportOptions{})
+
+	first, err := db.GetCSV(context.Background(), &QueryCSV{ID: id, Sort: "-Price", Limit: 2, Format: "objects"})
+	if err != nil {
+		t.Fatalf("GetCSV: %v", err)
+	}
+	if first.Next == "" {
+		t.Fatal("first page has no cursor")
+	}
+
+	if err := db.PersistToTurso(context.Background(), id); err != nil {
+		t.Fatalf("PersistToTurso: %v", err)
+	}
+
+	got := queryColumn(t, db, QueryCSV{ID: id, Sort: "-Price", Limit: 2, Cursor: first.Next}, "Name")
+	want := []string{"Cherry", "Apple"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("second page %v, want %v", got, want)
+	}
+}
+
+func TestMergeAppend(t *testing.T) {
+	for _, persisted := range []bool{false, true} {
+		db := newTestDB(t)
+		id := importTestCSV(t, db, testCSV, ImportOptions{Writable: true, KeyColumn: "Name"})
+		if persisted {
+			if err := db.PersistToTurso(context.Background(), id); err != nil {
+				t.Fatalf("PersistToTurso: %v", err)
+			}
+		}
+
+		changes := "Name,Price,Sold\nBanana,130,2024-03-05\nElderberry,12,2024-04-01\nApple,5,2024-01-15\n"
+		result, err := db.AppendCSVFromReader(context.Background(), id, strings.NewReader(changes), AppendOptions{
+			Mode:          AppendModeMerge,
+			KeyColumns:    []string{"Name"},
+			DeleteMissing: true,
+		})
+		if err != nil {
+			t.Fatalf("AppendCSVFromReader (persisted %v): %v", persisted, err)
+		}
+		if result.Inserted != 1 || result.Updated != 2 || result.Deleted != 2 {
+			t.Fatalf("persisted %v: inserted %d, updated %d, deleted %d, want 1, 2, 2",
+				persisted, result.Inserted, result.Updated, result.Deleted)
+		}
+
+		got := queryColumn(t, db, QueryCSV{ID: id, Sort: "Price"}, "Name")
+		want := []string{"Apple", "Elderberry", "Banana"}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Fatalf("persisted %v: rows by price %v, want %v", persisted, got, want)
+		}
+
+		// Merged rows keep their row ids and new rows continue after the
+		// imported ones.
+		rowIDs := queryColumn(t, db, QueryCSV{ID: id, Sort: "Name"}, RowIDColumn)
+		wantIDs := []int64{1, 2, 5}
+		if len(rowIDs) != len(wantIDs) {
+			t.Fatalf("persisted %v: row ids %v, want %v", persisted, rowIDs, wantIDs)
+		}
+		for i, rowID := range rowIDs {
+			if rowID != wantIDs[i] {
+				t.Fatalf("persisted %v: row ids %v, want %v", persisted, rowIDs, wantIDs)
+			}
+		}
+	}
+}
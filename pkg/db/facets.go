@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultFacetSize = 10
+	maxFacetSize     = 100
+
+	// suggestFacetLimit is the largest number of distinct values for a column
+	// to be suggested as a facet.
+	suggestFacetLimit = 30
+)
+
+// Facet holds the most common values of a column and their row counts.
+// Truncated is set when the column has more values than were returned.
+type Facet struct {
+	Column    string
+	Values    []FacetValue
+	Truncated bool
+}
+
+type FacetValue struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+// facetCounts counts the values of each facet column over the rows matching
+// where, most common first.
+func facetCounts(ctx context.Context, conn queryer, table string, facets []string, size int, columns []ColumnInfo, where string, args []any) ([]Facet, error) {
+	if size <= 0 {
+		size = defaultFacetSize
+	}
+	if size > maxFacetSize {
+		return nil, fmt.Errorf("%w: facet size cannot exceed %d", ErrInvalidInput, maxFacetSize)
+	}
+
+	var result []Facet
+	seen := make(map[string]bool)
+
+	for _, name := range facets {
+		col, ok := findColumn(columns, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown facet column %q", ErrInvalidInput, name)
+		}
+		if seen[col.Name] {
+			continue
+		}
+		seen[col.Name] = true
+
+		query := fmt.Sprintf("SELECT %s, COUNT(*) AS count FROM %s", quoteIdent(col.Name), table)
+		if where != "" {
+			query += " WHERE " + where
+		}
+		query += fmt.Sprintf(" GROUP BY %s ORDER BY count DESC, %s LIMIT %d",
+			quoteIdent(col.Name), quoteIdent(col.Name), size+1)
+
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count facet %q: %w", col.Name, err)
+		}
+
+		facet := Facet{Column: col.Name, Values: []FacetValue{}}
+		for rows.Next() {
+			var fv FacetValue
+			if err := rows.Scan(&fv.Value, &fv.Count); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan facet %q: %w", col.Name, err)
+			}
+			if b, ok := fv.Value.([]byte); ok {
+				fv.Value = string(b)
+			}
+			facet.Values = append(facet.Values, fv)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating facet %q: %w", col.Name, err)
+		}
+
+		if len(facet.Values) > size {
+			facet.Values = facet.Values[:size]
+			facet.Truncated = true
+		}
+
+		result = append(result, facet)
+	}
+
+	return result, nil
+}
+
+// suggestFacets returns the columns with few distinct values compared to the
+// number of rows, which make useful facets. Columns are suggested when they
+// have at most suggestFacetLimit values, each used by two rows on average.
+func suggestFacets(ctx context.Context, conn queryer, table string) ([]string, error) {
+	columns, err := tableColumns(ctx, conn, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	exprs := []string{"COUNT(*)"}
+	for _, col := range columns {
+		if col.Name == RowIDColumn {
+			continue
+		}
+		candidates = append(candidates, col.Name)
+		exprs = append(exprs, fmt.Sprintf("COUNT(DISTINCT %s)", quoteIdent(col.Name)))
+	}
+
+	counts := make([]int64, len(exprs))
+	dest := make([]any, len(exprs))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), table)
+	if err := conn.QueryRowContext(ctx, query).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to count distinct values: %w", err)
+	}
+
+	total := counts[0]
+	suggested := []string{}
+	for i, name := range candidates {
+		distinct := counts[i+1]
+		if distinct > 0 && distinct <= suggestFacetLimit && distinct*2 <= total {
+			suggested = append(suggested, name)
+		}
+	}
+
+	return suggested, nil
+}
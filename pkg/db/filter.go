@@ -0,0 +1,139 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// filterOperators maps filter operators to SQL comparison operators. The
+// pattern and set operators are built separately in filterCondition.
+var filterOperators = map[string]string{
+	"eq":         "=",
+	"ne":         "<>",
+	"gt":         ">",
+	"gte":        ">=",
+	"lt":         "<",
+	"lte":        "<=",
+	"contains":   "",
+	"startswith": "",
+	"endswith":   "",
+	"in":         "",
+	"notin":      "",
+	"isnull":     "",
+	"notnull":    "",
+}
+
+// filter is one row condition of the form column:op:value.
+type filter struct {
+	Column ColumnInfo
+	Op     string
+	Value  string
+}
+
+// parseFilters parses filters such as "Category:eq:Books", "Price:gte:10",
+// "Status:in:open|pending" or "Email:isnull". The column is everything before
+// the first known operator so values may contain colons.
+func parseFilters(filters []string, columns []ColumnInfo) ([]filter, error) {
+	parsed := make([]filter, 0, len(filters))
+
+	for _, f := range filters {
+		if f == "" {
+			continue
+		}
+
+		parts := strings.Split(f, ":")
+		found := false
+
+		for i := 1; i < len(parts); i++ {
+			op := strings.ToLower(parts[i])
+			if _, ok := filterOperators[op]; !ok {
+				continue
+			}
+
+			name := strings.Join(parts[:i], ":")
+			col, ok := findColumn(columns, name)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown filter column %q", ErrInvalidInput, name)
+			}
+
+			parsed = append(parsed, filter{
+				Column: col,
+				Op:     op,
+				Value:  strings.Join(parts[i+1:], ":"),
+			})
+			found = true
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: invalid filter %q, expected column:op:value", ErrInvalidInput, f)
+		}
+	}
+
+	return parsed, nil
+}
+
+// filterWhere parses filters and returns their SQL condition and arguments.
+func filterWhere(filters []string, columns []ColumnInfo) (string, []any, error) {
+	parsed, err := parseFilters(filters, columns)
+	if err != nil {
+		return "", nil, err
+	}
+	return filterCondition(parsed)
+}
+
+// filterCondition returns the conjunction of the filters as a SQL condition
+// with its arguments, or an empty string when there are no filters. Values
+// are converted to the column type so comparisons are not made on text.
+func filterCondition(filters []filter) (string, []any, error) {
+	var conditions []string
+	var args []any
+
+	for _, f := range filters {
+		col := quoteIdent(f.Column.Name)
+
+		switch f.Op {
+		case "isnull":
+			conditions = append(conditions, col+" IS NULL")
+		case "notnull":
+			conditions = append(conditions, col+" IS NOT NULL")
+		case "contains", "startswith", "endswith":
+			pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(f.Value)
+			switch f.Op {
+			case "contains":
+				pattern = "%" + pattern + "%"
+			case "startswith":
+				pattern += "%"
+			case "endswith":
+				pattern = "%" + pattern
+			}
+			conditions = append(conditions, fmt.Sprintf(`CAST(%s AS VARCHAR) LIKE ? ESCAPE '\'`, col))
+			args = append(args, pattern)
+		case "in", "notin":
+			values := strings.Split(f.Value, "|")
+			placeholders := make([]string, len(values))
+			for i, v := range values {
+				val, err := coerceValue(f.Column, v)
+				if err != nil {
+					return "", nil, err
+				}
+				placeholders[i] = "?"
+				args = append(args, val)
+			}
+			op := "IN"
+			if f.Op == "notin" {
+				op = "NOT IN"
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s (%s)", col, op, strings.Join(placeholders, ", ")))
+		default:
+			val, err := coerceValue(f.Column, f.Value)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s ?", col, filterOperators[f.Op]))
+			args = append(args, val)
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations spells out letters that do not decompose into an ASCII
+// letter and combining marks.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'ø': "o", 'Ø': "O", 'œ': "oe", 'Œ': "OE",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "TH",
+}
+
+// normalizeHeader turns a CSV header into a lower case snake_case ASCII name,
+// e.g. "Consommation de CP" into consommation_de_cp and "CustomerID" into
+// customer_id. Accents are dropped and other characters become separators.
+func normalizeHeader(header string) string {
+	var ascii []rune
+	for _, r := range norm.NFKD.String(header) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop the accents split off by the decomposition.
+		case r < unicode.MaxASCII:
+			ascii = append(ascii, r)
+		case transliterations[r] != "":
+			ascii = append(ascii, []rune(transliterations[r])...)
+		default:
+			ascii = append(ascii, ' ')
+		}
+	}
+
+	isAlnum := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+	}
+
+	var b strings.Builder
+	separate := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+
+	for i, r := range ascii {
+		if !isAlnum(r) {
+			separate()
+			continue
+		}
+		// Split camelCase words and acronyms followed by a word, as in
+		// HTTPServer.
+		if unicode.IsUpper(r) && i > 0 {
+			prev := ascii[i-1]
+			nextLower := i+1 < len(ascii) && unicode.IsLower(ascii[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				separate()
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// normalizeHeaders normalizes a CSV header row. Empty names become column_N
+// after their position and repeated names get a _2, _3, ... suffix, as do
+// the names of the columns added by the API, RowIDColumn and ScoreColumn.
+func normalizeHeaders(headers []string) []string {
+	names := make([]string, len(headers))
+	used := map[string]bool{RowIDColumn: true, ScoreColumn: true}
+	for i, header := range headers {
+		names[i] = normalizeHeader(header)
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("column_%d", i+1)
+		}
+		used[names[i]] = true
+	}
+
+	seen := map[string]bool{RowIDColumn: true, ScoreColumn: true}
+	for i, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			continue
+		}
+		n := 2
+		for used[fmt.Sprintf("%s_%d", name, n)] {
+			n++
+		}
+		names[i] = fmt.Sprintf("%s_%d", name, n)
+		used[names[i]] = true
+		seen[names[i]] = true
+	}
+
+	return names
+}
+
+// csvHeaders returns the header row of a CSV file as written, before DuckDB
+// renames empty and repeated names. It returns nil when the file has no
+// header.
+func csvHeaders(ctx context.Context, conn queryer, path string) ([]string, error) {
+	var hasHeader bool
+	if err := conn.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT HasHeader FROM sniff_csv(%s)", quoteString(path))).Scan(&hasHeader); err != nil {
+		return nil, fmt.Errorf("failed to detect CSV header: %w", err)
+	}
+	if !hasHeader {
+		return nil, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		"SELECT * FROM read_csv_auto(%s, header=false, all_varchar=true, strict_mode=false) LIMIT 1", quoteString(path)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan CSV header: %w", err)
+	}
+
+	headers := make([]string, len(values))
+	for i, v := range values {
+		headers[i] = v.String
+	}
+	return headers, nil
+}
+
+// readCSVSource returns a query reading the CSV file at path. With normalize
+// the columns are renamed by normalizeHeaders and the returned labels map
+// each new name to the header as written in the file.
+func readCSVSource(ctx context.Context, conn queryer, path string, normalize bool) (string, map[string]string, error) {
+	query := readCSVQuery(path)
+	if !normalize {
+		return query, nil, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, "DESCRIBE "+query)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read CSV columns: %w", err)
+	}
+	var detected []string
+	for rows.Next() {
+		var name, colType string
+		var null, key, def, extra sql.NullString
+		if err := rows.Scan(&name, &colType, &null, &key, &def, &extra); err != nil {
+			rows.Close()
+			return "", nil, fmt.Errorf("failed to scan CSV columns: %w", err)
+		}
+		detected = append(detected, name)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return "", nil, fmt.Errorf("error iterating CSV columns: %w", err)
+	}
+
+	headers, err := csvHeaders(ctx, conn, path)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(headers) != len(detected) {
+		headers = detected
+	}
+
+	names := normalizeHeaders(headers)
+	labels := make(map[string]string, len(names))
+	selected := make([]string, len(names))
+	for i, name := range names {
+		labels[name] = headers[i]
+		selected[i] = quoteIdent(detected[i]) + " AS " + quoteIdent(name)
+	}
+
+	return fmt.Sprintf("SELECT %s FROM (%s)", strings.Join(selected, ", "), query), labels, nil
+}
+
+// resolveLabel returns the column name for a column referred to by name or
+// by its original label.
+func resolveLabel(labels map[string]string, name string) string {
+	if _, ok := labels[name]; ok {
+		return name
+	}
+	if column := normalizeHeader(name); labels[column] == name {
+		return column
+	}
+	return name
+}
+
+func resolveLabels(labels map[string]string, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	resolved := make([]string, len(names))
+	for i, name := range names {
+		resolved[i] = resolveLabel(labels, name)
+	}
+	return resolved
+}
+
+// addColumnLabels stores the labels of the columns added to a dataset with
+// normalized headers by an append. Failures are logged, the rows are already
+// written.
+func (db *DB) addColumnLabels(ctx context.Context, csvTable *CSVTable, labels map[string]string, added []ColumnInfo) {
+	if csvTable.ColumnLabels == nil || len(added) == 0 {
+		return
+	}
+
+	for _, col := range added {
+		csvTable.ColumnLabels[col.Name] = labels[col.Name]
+	}
+
+	data, _ := json.Marshal(csvTable.ColumnLabels)
+	if _, err := db.tursoConn.ExecContext(ctx,
+		"UPDATE csv_table SET column_labels = ? WHERE id = ?", string(data), csvTable.ID); err != nil {
+		slog.ErrorContext(ctx, "Error storing column labels", "error", err)
+	}
+}
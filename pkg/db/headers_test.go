@@ -0,0 +1,24 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeHeaders(t *testing.T) {
+	tests := []struct {
+		headers []string
+		want    []string
+	}{
+		{[]string{"Consommation de CP", "CustomerID", "HTTPServer"}, []string{"consommation_de_cp", "customer_id", "http_server"}},
+		{[]string{"Name", "name", "NAME", "name_2"}, []string{"name", "name_3", "name_4", "name_2"}},
+		{[]string{"", "Price", "  "}, []string{"column_1", "price", "column_3"}},
+		{[]string{"_rowid", "_score"}, []string{"rowid", "score"}},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeHeaders(tt.headers); !slices.Equal(got, tt.want) {
+			t.Errorf("normalizeHeaders(%q) = %q, want %q", tt.headers, got, tt.want)
+		}
+	}
+}
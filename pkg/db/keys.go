@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/marcboeker/go-duckdb/v2"
+)
+
+const maxReportedDuplicates = 10
+
+// execer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// validateIndexes checks that the key and indexed columns of csvTable exist
+// and that the key column holds unique, non-null values.
+func validateIndexes(ctx context.Context, conn queryer, csvTable *CSVTable) error {
+	columns, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range csvTable.IndexedColumns {
+		if _, ok := findColumn(columns, name); !ok {
+			return fmt.Errorf("%w: unknown index column %q", ErrInvalidInput, name)
+		}
+	}
+
+	if csvTable.KeyColumn == "" {
+		return nil
+	}
+
+	if _, ok := findColumn(columns, csvTable.KeyColumn); !ok {
+		return fmt.Errorf("%w: unknown key column %q", ErrInvalidInput, csvTable.KeyColumn)
+	}
+
+	key := quoteIdent(csvTable.KeyColumn)
+
+	var nulls int
+	err = conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL",
+		csvTable.TableName, key)).Scan(&nulls)
+	if err != nil {
+		return fmt.Errorf("failed to check key column: %w", err)
+	}
+
+	if nulls > 0 {
+		return fmt.Errorf("%w: key column %q has %d null values", ErrConflict, csvTable.KeyColumn, nulls)
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		"SELECT CAST(%s AS VARCHAR), COUNT(*) FROM %s GROUP BY %s HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC LIMIT %d",
+		key, csvTable.TableName, key, maxReportedDuplicates))
+	if err != nil {
+		return fmt.Errorf("failed to check key column: %w", err)
+	}
+	defer rows.Close()
+
+	var duplicates []string
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return fmt.Errorf("failed to scan duplicate key: %w", err)
+		}
+		duplicates = append(duplicates, fmt.Sprintf("%q (%d rows)", value, count))
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating duplicate keys: %w", err)
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%w: key column %q has duplicate values: %s",
+			ErrConflict, csvTable.KeyColumn, strings.Join(duplicates, ", "))
+	}
+
+	return nil
+}
+
+// keyConflict marks err with ErrConflict when it reports a constraint
+// violation, such as a duplicate value of the key column, by DuckDB or Turso.
+func keyConflict(err error) error {
+	var duckErr *duckdb.Error
+	if (errors.As(err, &duckErr) && duckErr.Type == duckdb.ErrorTypeConstraint) ||
+		strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
+
+// createIndexes creates the unique key index and the column indexes of
+// csvTable on tableName.
+func createIndexes(ctx context.Context, conn execer, csvTable *CSVTable, tableName string) error {
+	if csvTable.KeyColumn != "" {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE UNIQUE INDEX %s_key ON %s (%s)",
+			tableName, tableName, quoteIdent(csvTable.KeyColumn)))
+		if err != nil {
+			return keyConflict(fmt.Errorf("failed to create key index: %w", err))
+		}
+	}
+
+	for i, name := range csvTable.IndexedColumns {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE INDEX %s_idx_%d ON %s (%s)",
+			tableName, i, tableName, quoteIdent(name)))
+		if err != nil {
+			return fmt.Errorf("failed to create index on %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// dropIndexes drops the indexes created by createIndexes. DuckDB refuses to
+// alter a table with indexes, so they are dropped around schema changes.
+func dropIndexes(ctx context.Context, conn execer, csvTable *CSVTable, tableName string) error {
+	names := make([]string, 0, len(csvTable.IndexedColumns)+1)
+	if csvTable.KeyColumn != "" {
+		names = append(names, tableName+"_key")
+	}
+	for i := range csvTable.IndexedColumns {
+		names = append(names, fmt.Sprintf("%s_idx_%d", tableName, i))
+	}
+
+	for _, name := range names {
+		if _, err := conn.ExecContext(ctx, "DROP INDEX IF EXISTS "+name); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
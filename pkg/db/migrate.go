@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	migrationLockTimeout = 30 * time.Second
+	migrationLockStale   = 5 * time.Minute
+)
+
+// Migration is a single versioned schema change for the metadata database.
+// Migrations are embedded from migrations/NNNN_name.sql and applied in
+// version order.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration filename %q", entry.Name())
+		}
+
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (db *DB) ensureMigrationTables(ctx context.Context) error {
+	_, err := db.tursoConn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	_, err = db.tursoConn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			locked_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations_lock: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) appliedMigrations(ctx context.Context) (map[int]bool, error) {
+	rows, err := db.tursoConn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		applied[version] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating migration rows: %w", err)
+	}
+
+	return applied, nil
+}
+
+// acquireMigrationLock takes the single row lock in schema_migrations_lock so
+// that only one server instance applies migrations at a time. Locks older than
+// migrationLockStale are assumed to belong to a crashed process and are cleared.
+func (db *DB) acquireMigrationLock(ctx context.Context) error {
+	deadline := time.Now().Add(migrationLockTimeout)
+
+	for {
+		now := time.Now().UTC()
+
+		_, err := db.tursoConn.ExecContext(ctx,
+			"DELETE FROM schema_migrations_lock WHERE locked_at < ?", now.Add(-migrationLockStale))
+		if err != nil {
+			return fmt.Errorf("failed to clear stale migration lock: %w", err)
+		}
+
+		_, err = db.tursoConn.ExecContext(ctx,
+			"INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)", now)
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for migration lock: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (db *DB) releaseMigrationLock(ctx context.Context) {
+	if _, err := db.tursoConn.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE id = 1"); err != nil {
+		slog.ErrorContext(ctx, "Error releasing migration lock", "error", err)
+	}
+}
+
+// PendingMigrations returns the embedded migrations that have not yet been
+// applied to the metadata database, in the order they would be applied.
+func (db *DB) PendingMigrations(ctx context.Context) ([]Migration, error) {
+	if err := db.ensureMigrationTables(ctx); err != nil {
+		return nil, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// Migrate applies all pending migrations while holding the migration lock and
+// returns the migrations that were applied. Each migration runs in its own
+// transaction together with its schema_migrations record.
+func (db *DB) Migrate(ctx context.Context) ([]Migration, error) {
+	if err := db.ensureMigrationTables(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := db.acquireMigrationLock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.releaseMigrationLock(ctx)
+
+	// Re-read pending migrations under the lock, another instance may have
+	// applied them while we were waiting.
+	pending, err := db.PendingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		if err := db.applyMigration(ctx, m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+func (db *DB) applyMigration(ctx context.Context, m Migration) (err error) {
+	tx, err := db.tursoConn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	return nil
+}
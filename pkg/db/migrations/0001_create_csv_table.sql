@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS csv_table (
+	id TEXT PRIMARY KEY,
+	filename TEXT NOT NULL,
+	table_name TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	persisted BOOLEAN DEFAULT 0
+);
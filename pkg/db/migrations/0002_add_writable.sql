@@ -0,0 +1 @@
+ALTER TABLE csv_table ADD COLUMN writable BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE csv_table ADD COLUMN key_column TEXT;
+ALTER TABLE csv_table ADD COLUMN indexed_columns TEXT;
@@ -0,0 +1 @@
+ALTER TABLE csv_table ADD COLUMN suggested_facets TEXT;
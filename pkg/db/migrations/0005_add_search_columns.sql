@@ -0,0 +1 @@
+ALTER TABLE csv_table ADD COLUMN search_columns TEXT;
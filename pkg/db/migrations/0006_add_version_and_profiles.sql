@@ -0,0 +1,8 @@
+ALTER TABLE csv_table ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS dataset_profiles (
+	id TEXT PRIMARY KEY,
+	version INTEGER NOT NULL,
+	profile TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
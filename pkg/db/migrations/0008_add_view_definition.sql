@@ -0,0 +1 @@
+ALTER TABLE csv_table ADD COLUMN view_definition TEXT;
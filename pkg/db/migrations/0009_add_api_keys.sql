@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	owner TEXT NOT NULL,
+	admin INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	last_used_at TIMESTAMP,
+	revoked_at TIMESTAMP
+);
+
+ALTER TABLE csv_table ADD COLUMN owner TEXT;
+
+CREATE INDEX IF NOT EXISTS csv_table_owner ON csv_table (owner);
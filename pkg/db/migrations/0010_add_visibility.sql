@@ -0,0 +1 @@
+ALTER TABLE csv_table ADD COLUMN visibility TEXT NOT NULL DEFAULT 'private';
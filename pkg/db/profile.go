@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	profileTopValues    = 5
+	profileSampleValues = 5
+)
+
+// Profile describes the columns of a dataset at a version.
+type Profile struct {
+	Version     int64           `json:"version"`
+	RowCount    int64           `json:"row_count"`
+	Columns     []ColumnProfile `json:"columns"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// ColumnProfile holds the statistics of a single column. Mean and StdDev
+// are only set for numeric columns.
+type ColumnProfile struct {
+	Name          string       `json:"name"`
+	Type          string       `json:"type"`
+	NullCount     int64        `json:"null_count"`
+	DistinctCount int64        `json:"distinct_count"`
+	Min           any          `json:"min"`
+	Max           any          `json:"max"`
+	Mean          *float64     `json:"mean,omitempty"`
+	StdDev        *float64     `json:"stddev,omitempty"`
+	TopValues     []FacetValue `json:"top_values"`
+	SampleValues  []any        `json:"sample_values"`
+}
+
+// datasetChanged records that the rows of a dataset are changing: the
+// version is increased, which invalidates cached profiles and search indexes,
+// and the suggested facets are recomputed from the rows written by tx. It
+// runs in the write's transaction tx before it commits, so rows never change
+// without their metadata. Persisted rows live in the metadata database and
+// update it within tx. DuckDB writes update it just before committing and
+// are rolled back when that fails. An update without a write only costs a
+// cache refresh.
+func (db *DB) datasetChanged(ctx context.Context, tx *sql.Tx, csvTable *CSVTable) error {
+	suggested, err := suggestFacets(ctx, tx, csvTable.TableName)
+	if err != nil {
+		return err
+	}
+
+	var suggestedFacets sql.NullString
+	if suggested != nil {
+		data, _ := json.Marshal(suggested)
+		suggestedFacets = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var conn execer = tx
+	if !csvTable.Persisted {
+		conn = db.tursoConn
+	}
+
+	if _, err := conn.ExecContext(ctx,
+		"UPDATE csv_table SET version = version + 1, suggested_facets = ? WHERE id = ?",
+		suggestedFacets, csvTable.ID); err != nil {
+		return fmt.Errorf("failed to update dataset version: %w", err)
+	}
+	csvTable.Version++
+	csvTable.SuggestedFacets = suggested
+
+	return nil
+}
+
+// GetProfile returns the column statistics of a dataset, computed at most
+// once per dataset version. The boolean reports whether the profile came
+// from the cache.
+func (db *DB) GetProfile(ctx context.Context, id string) (*Profile, bool, error) {
+	csvTable, err := db.GetCSVTable(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The rows of virtual views change with their sources without changing
+	// the view's version.
+	if csvTable.isVirtual() {
+		profile, err := db.profile(ctx, csvTable)
+		return profile, false, err
+	}
+
+	var data string
+	err = db.tursoConn.QueryRowContext(ctx,
+		"SELECT profile FROM dataset_profiles WHERE id = ? AND version = ?", id, csvTable.Version).Scan(&data)
+	if err == nil {
+		var profile Profile
+		if err := json.Unmarshal([]byte(data), &profile); err == nil {
+			return &profile, true, nil
+		}
+		slog.ErrorContext(ctx, "Error reading cached profile", "error", err)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to read cached profile: %w", err)
+	}
+
+	profile, err := db.profile(ctx, csvTable)
+	if err != nil {
+		return nil, false, err
+	}
+
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	_, err = db.tursoConn.ExecContext(ctx, `
+		INSERT INTO dataset_profiles (id, version, profile, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET version = excluded.version, profile = excluded.profile, created_at = excluded.created_at
+	`, id, profile.Version, string(encoded), profile.GeneratedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error caching profile", "error", err)
+	}
+
+	return profile, false, nil
+}
+
+func (db *DB) profile(ctx context.Context, csvTable *CSVTable) (*Profile, error) {
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, err
+	}
+
+	tableCols, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []ColumnInfo
+	for _, col := range tableCols {
+		if col.Name != RowIDColumn {
+			columns = append(columns, col)
+		}
+	}
+
+	profile := &Profile{
+		Version:     csvTable.Version,
+		Columns:     make([]ColumnProfile, len(columns)),
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	for i, col := range columns {
+		profile.Columns[i] = ColumnProfile{Name: col.Name, Type: col.Type}
+	}
+
+	if err := profileCounts(ctx, conn, csvTable.TableName, columns, profile); err != nil {
+		return nil, err
+	}
+
+	if csvTable.Persisted {
+		err = profileTextStats(ctx, conn, csvTable.TableName, columns, profile)
+	} else {
+		err = profileSummarize(ctx, conn, csvTable.TableName, profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+
+	top, err := facetCounts(ctx, conn, csvTable.TableName, names, profileTopValues, columns, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, col := range columns {
+		profile.Columns[i].TopValues = top[i].Values
+
+		samples, err := sampleValues(ctx, conn, csvTable.TableName, col.Name)
+		if err != nil {
+			return nil, err
+		}
+		profile.Columns[i].SampleValues = samples
+	}
+
+	return profile, nil
+}
+
+// profileCounts sets the row count and the exact null and distinct counts of
+// every column with a single scan.
+func profileCounts(ctx context.Context, conn queryer, table string, columns []ColumnInfo, profile *Profile) error {
+	exprs := []string{"COUNT(*)"}
+	for _, col := range columns {
+		exprs = append(exprs,
+			fmt.Sprintf("COUNT(%s)", quoteIdent(col.Name)),
+			fmt.Sprintf("COUNT(DISTINCT %s)", quoteIdent(col.Name)))
+	}
+
+	counts := make([]int64, len(exprs))
+	dest := make([]any, len(exprs))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), table)
+	if err := conn.QueryRowContext(ctx, query).Scan(dest...); err != nil {
+		return fmt.Errorf("failed to count column values: %w", err)
+	}
+
+	profile.RowCount = counts[0]
+	for i := range columns {
+		profile.Columns[i].NullCount = counts[0] - counts[1+2*i]
+		profile.Columns[i].DistinctCount = counts[2+2*i]
+	}
+
+	return nil
+}
+
+// profileSummarize reads types, min, max, mean and standard deviation from
+// DuckDB's SUMMARIZE.
+func profileSummarize(ctx context.Context, conn queryer, table string, profile *Profile) error {
+	rows, err := conn.QueryContext(ctx,
+		fmt.Sprintf(`SELECT column_name, column_type, "min", "max", "avg", "std" FROM (SUMMARIZE %s)`, table))
+	if err != nil {
+		return fmt.Errorf("failed to summarize dataset: %w", err)
+	}
+	defer rows.Close()
+
+	byName := make(map[string]*ColumnProfile, len(profile.Columns))
+	for i := range profile.Columns {
+		byName[profile.Columns[i].Name] = &profile.Columns[i]
+	}
+
+	for rows.Next() {
+		var name, colType string
+		var minVal, maxVal, avg, std sql.NullString
+		if err := rows.Scan(&name, &colType, &minVal, &maxVal, &avg, &std); err != nil {
+			return fmt.Errorf("failed to scan summary: %w", err)
+		}
+
+		col, ok := byName[name]
+		if !ok {
+			continue
+		}
+
+		col.Type = colType
+		if minVal.Valid {
+			col.Min = minVal.String
+		}
+		if maxVal.Valid {
+			col.Max = maxVal.String
+		}
+		col.Mean = parseStat(avg)
+		col.StdDev = parseStat(std)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating summary: %w", err)
+	}
+
+	return nil
+}
+
+func parseStat(s sql.NullString) *float64 {
+	if !s.Valid {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s.String, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
+
+// profileTextStats computes min, max, mean and standard deviation for the
+// columns of a persisted table. SQLite columns can hold values of any type,
+// so mean and standard deviation are only computed for columns where every
+// value is a number.
+func profileTextStats(ctx context.Context, conn queryer, table string, columns []ColumnInfo, profile *Profile) error {
+	for i, col := range columns {
+		name := quoteIdent(col.Name)
+		isNumber := fmt.Sprintf("(%s <> '' AND NOT %s GLOB '*[^0-9.eE+-]*')", name, name)
+
+		var minVal, maxVal any
+		var numbers, nonNull int64
+		var sum, sumSquares sql.NullFloat64
+
+		query := fmt.Sprintf(`SELECT MIN(%[1]s), MAX(%[1]s), COUNT(%[1]s),
+			SUM(CASE WHEN %[2]s THEN 1 ELSE 0 END),
+			SUM(CASE WHEN %[2]s THEN CAST(%[1]s AS REAL) END),
+			SUM(CASE WHEN %[2]s THEN CAST(%[1]s AS REAL) * CAST(%[1]s AS REAL) END)
+			FROM %[3]s`, name, isNumber, table)
+
+		err := conn.QueryRowContext(ctx, query).Scan(&minVal, &maxVal, &nonNull, &numbers, &sum, &sumSquares)
+		if err != nil {
+			return fmt.Errorf("failed to profile column %q: %w", col.Name, err)
+		}
+
+		p := &profile.Columns[i]
+		p.Min = stringifyValue(minVal)
+		p.Max = stringifyValue(maxVal)
+
+		if nonNull > 0 && numbers == nonNull {
+			n := float64(numbers)
+			mean := sum.Float64 / n
+			p.Mean = &mean
+			if numbers > 1 {
+				variance := (sumSquares.Float64 - n*mean*mean) / (n - 1)
+				stddev := math.Sqrt(math.Max(variance, 0))
+				p.StdDev = &stddev
+			}
+		}
+	}
+
+	return nil
+}
+
+func stringifyValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func sampleValues(ctx context.Context, conn queryer, table, column string) ([]any, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		quoteIdent(column), table, quoteIdent(column), profileSampleValues))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample column %q: %w", column, err)
+	}
+	defer rows.Close()
+
+	samples := []any{}
+	for rows.Next() {
+		var v any
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to scan sample of %q: %w", column, err)
+		}
+		samples = append(samples, stringifyValue(v))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating samples of %q: %w", column, err)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,279 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// expressionFunctions are the SQL functions allowed in computed columns. All
+// of them behave the same in DuckDB and SQLite.
+var expressionFunctions = map[string]bool{
+	"abs":      true,
+	"coalesce": true,
+	"length":   true,
+	"lower":    true,
+	"ltrim":    true,
+	"nullif":   true,
+	"replace":  true,
+	"round":    true,
+	"rtrim":    true,
+	"substr":   true,
+	"trim":     true,
+	"upper":    true,
+}
+
+// projection returns the select list of a query: the columns listed in
+// columns, or all table columns, minus the excluded columns. columns is a
+// comma separated list of column names and aliased expressions such as
+// "Price*Quantity as total".
+func projection(columns, exclude string, tableCols []ColumnInfo, hideRowID bool) ([]string, error) {
+	excluded := make(map[string]bool)
+	for _, name := range splitTopLevel(exclude) {
+		col, ok := findColumn(tableCols, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown excluded column %q", ErrInvalidInput, name)
+		}
+		excluded[col.Name] = true
+	}
+	if hideRowID {
+		excluded[RowIDColumn] = true
+	}
+
+	var selected []string
+	seen := make(map[string]bool)
+
+	add := func(name, expr string) error {
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate column %q", ErrInvalidInput, name)
+		}
+		seen[name] = true
+		selected = append(selected, expr)
+		return nil
+	}
+
+	if columns == "" {
+		for _, col := range tableCols {
+			if !excluded[col.Name] {
+				selected = append(selected, quoteIdent(col.Name))
+			}
+		}
+		return selected, nil
+	}
+
+	for _, item := range splitTopLevel(columns) {
+		if col, ok := findColumn(tableCols, item); ok {
+			if excluded[col.Name] {
+				continue
+			}
+			if err := add(col.Name, quoteIdent(col.Name)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		i := strings.LastIndex(strings.ToLower(item), " as ")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: unknown column %q, expressions need an alias as in \"a*b as total\"", ErrInvalidInput, item)
+		}
+
+		alias := strings.TrimSpace(item[i+len(" as "):])
+		if alias == "" {
+			return nil, fmt.Errorf("%w: missing alias in %q", ErrInvalidInput, item)
+		}
+		if _, ok := findColumn(tableCols, alias); ok {
+			return nil, fmt.Errorf("%w: alias %q shadows a column", ErrInvalidInput, alias)
+		}
+
+		expr, err := compileExpression(item[:i], tableCols)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := add(alias, expr+" AS "+quoteIdent(alias)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: no columns selected", ErrInvalidInput)
+	}
+
+	return selected, nil
+}
+
+// splitTopLevel splits s on commas outside of parentheses and quotes and
+// trims the parts, dropping empty ones.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	var quote rune
+	start := 0
+
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, s[start:])
+
+	var trimmed []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			trimmed = append(trimmed, p)
+		}
+	}
+	return trimmed
+}
+
+// compileExpression checks a computed column expression against the
+// whitelist and returns it as SQL. Expressions may use column names (bare or
+// double quoted), number and single quoted string literals, the arithmetic
+// operators + - * / %, || for concatenation, parentheses and the functions
+// in expressionFunctions. The SQL is rebuilt from the tokens so nothing else
+// can reach the query.
+func compileExpression(expr string, tableCols []ColumnInfo) (string, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: invalid expression %q: %s", ErrInvalidInput, strings.TrimSpace(expr), fmt.Sprintf(format, args...))
+	}
+
+	src := []rune(expr)
+	var tokens []string
+	depth := 0
+
+	for i := 0; i < len(src); {
+		r := src[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || r == '.':
+			j := i
+			for j < len(src) && (unicode.IsDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			if strings.Count(string(src[i:j]), ".") > 1 {
+				return "", invalid("malformed number %q", string(src[i:j]))
+			}
+			tokens = append(tokens, string(src[i:j]))
+			i = j
+
+		case r == '\'':
+			j := i + 1
+			var lit strings.Builder
+			for {
+				if j >= len(src) {
+					return "", invalid("unterminated string")
+				}
+				if src[j] == '\'' {
+					if j+1 < len(src) && src[j+1] == '\'' {
+						lit.WriteRune('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				lit.WriteRune(src[j])
+				j++
+			}
+			tokens = append(tokens, "'"+strings.ReplaceAll(lit.String(), "'", "''")+"'")
+			i = j + 1
+
+		case r == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				j++
+			}
+			if j >= len(src) {
+				return "", invalid("unterminated column name")
+			}
+			name := string(src[i+1 : j])
+			if _, ok := findColumn(tableCols, name); !ok {
+				return "", invalid("unknown column %q", name)
+			}
+			tokens = append(tokens, quoteIdent(name))
+			i = j + 1
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j]) || src[j] == '_') {
+				j++
+			}
+			name := string(src[i:j])
+
+			k := j
+			for k < len(src) && unicode.IsSpace(src[k]) {
+				k++
+			}
+
+			if k < len(src) && src[k] == '(' {
+				if !expressionFunctions[strings.ToLower(name)] {
+					return "", invalid("function %q is not allowed", name)
+				}
+				tokens = append(tokens, strings.ToLower(name))
+			} else {
+				if _, ok := findColumn(tableCols, name); !ok {
+					return "", invalid("unknown column %q", name)
+				}
+				tokens = append(tokens, quoteIdent(name))
+			}
+			i = j
+
+		case r == '|':
+			if i+1 >= len(src) || src[i+1] != '|' {
+				return "", invalid("unexpected %q", r)
+			}
+			tokens = append(tokens, "||")
+			i += 2
+
+		case strings.ContainsRune("+-*/%", r):
+			tokens = append(tokens, string(r))
+			i++
+
+		case r == '(':
+			depth++
+			tokens = append(tokens, string(r))
+			i++
+
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return "", invalid("unbalanced parentheses")
+			}
+			tokens = append(tokens, string(r))
+			i++
+
+		case r == ',':
+			if depth == 0 {
+				return "", invalid("unexpected %q", r)
+			}
+			tokens = append(tokens, ",")
+			i++
+
+		default:
+			return "", invalid("unexpected %q", r)
+		}
+	}
+
+	if depth != 0 {
+		return "", invalid("unbalanced parentheses")
+	}
+	if len(tokens) == 0 {
+		return "", invalid("empty expression")
+	}
+
+	return "(" + strings.Join(tokens, " ") + ")", nil
+}
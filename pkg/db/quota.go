@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Quota limits the storage of each owner. Zero values are unlimited. Admins
+// are not limited.
+type Quota struct {
+	// MaxBytes is the total size of the DuckDB files of an owner's datasets.
+	MaxBytes int64
+	// MaxDatasets counts datasets and views.
+	MaxDatasets int
+}
+
+// Usage is the storage used by an owner.
+type Usage struct {
+	Datasets int
+	Bytes    int64
+}
+
+// SetQuota sets the quota applied to every owner.
+func (db *DB) SetQuota(quota Quota) {
+	db.quota = quota
+}
+
+// Quota returns the quota applied to every owner.
+func (db *DB) Quota() Quota {
+	return db.quota
+}
+
+// Usage returns the storage used by the owner of ctx. Datasets created
+// without authentication are counted together.
+func (db *DB) Usage(ctx context.Context) (*Usage, error) {
+	rows, err := db.tursoConn.QueryContext(ctx,
+		"SELECT id FROM csv_table WHERE COALESCE(owner, '') = ?", contextOwner(ctx).String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+	defer rows.Close()
+
+	var usage Usage
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan dataset: %w", err)
+		}
+
+		usage.Datasets++
+		usage.Bytes += db.duckDBSize(id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating datasets: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// duckDBSize returns the size of the DuckDB file of a dataset and its WAL,
+// zero for virtual views.
+func (db *DB) duckDBSize(id string) int64 {
+	var size int64
+	dbPath := db.getDuckDBPath(id)
+	for _, path := range []string{dbPath, dbPath + ".wal"} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// checkQuota rejects adding data for the owner of ctx once its quota is used
+// up. newDatasets is the number of datasets about to be created.
+func (db *DB) checkQuota(ctx context.Context, newDatasets int) error {
+	if db.quota == (Quota{}) {
+		return nil
+	}
+	if p := PrincipalFromContext(ctx); p != nil && p.Admin {
+		return nil
+	}
+
+	usage, err := db.Usage(ctx)
+	if err != nil {
+		return err
+	}
+
+	if db.quota.MaxDatasets > 0 && usage.Datasets+newDatasets > db.quota.MaxDatasets {
+		return fmt.Errorf("%w: at most %d datasets are allowed", ErrQuota, db.quota.MaxDatasets)
+	}
+	if db.quota.MaxBytes > 0 && usage.Bytes >= db.quota.MaxBytes {
+		return fmt.Errorf("%w: %d of %d bytes of storage used", ErrQuota, usage.Bytes, db.quota.MaxBytes)
+	}
+
+	return nil
+}
+
+// checkStorageQuota rejects the new dataset id when its DuckDB file takes the
+// storage of the owner of ctx over its quota. The caller removes the dataset.
+func (db *DB) checkStorageQuota(ctx context.Context, id string) error {
+	if db.quota.MaxBytes == 0 {
+		return nil
+	}
+	if p := PrincipalFromContext(ctx); p != nil && p.Admin {
+		return nil
+	}
+
+	// Write the new rows to the file so its size is accurate.
+	db.mu.Lock()
+	conn, ok := db.duckDBMap[id]
+	db.mu.Unlock()
+	if ok {
+		if _, err := conn.ExecContext(ctx, "CHECKPOINT"); err != nil {
+			return fmt.Errorf("failed to checkpoint dataset: %w", err)
+		}
+	}
+
+	usage, err := db.Usage(ctx)
+	if err != nil {
+		return err
+	}
+
+	if bytes := usage.Bytes + db.duckDBSize(id); bytes > db.quota.MaxBytes {
+		return fmt.Errorf("%w: the dataset would use %d of %d bytes of storage", ErrQuota, bytes, db.quota.MaxBytes)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func rowIDSequence(tableName string) string {
+	return tableName + "_rowid_seq"
+}
+
+// createRowIDSequence makes new rows of a DuckDB table take the next row id
+// after the existing rows.
+func createRowIDSequence(ctx context.Context, conn *sql.DB, tableName string) error {
+	var next int64
+	err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) + 1 FROM %s", RowIDColumn, tableName)).Scan(&next)
+	if err != nil {
+		return fmt.Errorf("failed to get next row id: %w", err)
+	}
+
+	sequence := rowIDSequence(tableName)
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("CREATE SEQUENCE %s START WITH %d", sequence, next))
+	if err != nil {
+		return fmt.Errorf("failed to create row id sequence: %w", err)
+	}
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT nextval('%s')",
+		tableName, RowIDColumn, sequence))
+	if err != nil {
+		return fmt.Errorf("failed to set row id default: %w", err)
+	}
+
+	return nil
+}
+
+// upgradeRowIDColumn adds the row id column to DuckDB tables imported before
+// row ids were persisted, numbering existing rows in storage order.
+func upgradeRowIDColumn(ctx context.Context, conn *sql.DB, tableName string) error {
+	var exists bool
+	err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) > 0 FROM duckdb_tables() WHERE table_name = ?", tableName).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check for table %s: %w", tableName, err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	columns, err := tableColumns(ctx, conn, tableName)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := findColumn(columns, RowIDColumn); ok {
+		return nil
+	}
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s BIGINT", tableName, RowIDColumn))
+	if err != nil {
+		return fmt.Errorf("failed to add row id column: %w", err)
+	}
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s = rowid + 1", tableName, RowIDColumn))
+	if err != nil {
+		return fmt.Errorf("failed to number rows: %w", err)
+	}
+
+	return createRowIDSequence(ctx, conn, tableName)
+}
+
+// continueRowIDSequence sets the AUTOINCREMENT counter of a persisted table to
+// the DuckDB row id sequence, so ids of rows deleted before persisting are
+// not handed out again.
+func continueRowIDSequence(ctx context.Context, duckConn *sql.DB, tx *sql.Tx, duckTable, tursoTable string) error {
+	var next int64
+	err := duckConn.QueryRowContext(ctx, fmt.Sprintf("SELECT nextval('%s')", rowIDSequence(duckTable))).Scan(&next)
+	if err != nil {
+		return fmt.Errorf("failed to get next row id: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sqlite_sequence WHERE name = ?", tursoTable); err != nil {
+		return fmt.Errorf("failed to reset row id sequence: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO sqlite_sequence (name, seq) VALUES (?, ?)", tursoTable, next-1); err != nil {
+		return fmt.Errorf("failed to set row id sequence: %w", err)
+	}
+
+	return nil
+}
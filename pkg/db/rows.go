@@ -0,0 +1,513 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tableConn returns the connection holding the data of csvTable, the Turso
+// database once persisted and the dataset's DuckDB file otherwise.
+func (db *DB) tableConn(ctx context.Context, csvTable *CSVTable) (*sql.DB, error) {
+	if csvTable.Persisted {
+		return db.tursoConn, nil
+	}
+	if csvTable.isVirtual() {
+		return db.viewConn(ctx, csvTable)
+	}
+	return db.getDuckDBConnection(csvTable.ID)
+}
+
+// queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func tableColumns(ctx context.Context, conn queryer, tableName string) ([]ColumnInfo, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info('%s')", tableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table info: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []ColumnInfo
+	for rows.Next() {
+		var col ColumnInfo
+		if err := rows.Scan(&col.CID, &col.Name, &col.Type, &col.NotNull, &col.DefaultVal, &col.PK); err != nil {
+			return nil, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		columns = append(columns, col)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating column rows: %w", err)
+	}
+
+	return columns, nil
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func findColumn(columns []ColumnInfo, name string) (ColumnInfo, bool) {
+	for _, col := range columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return ColumnInfo{}, false
+}
+
+// integerRanges are the smallest and largest values of the integer column
+// types. Values are bound as int64, so the types larger than that are limited
+// to its range.
+var integerRanges = map[string][2]int64{
+	"TINYINT":   {math.MinInt8, math.MaxInt8},
+	"SMALLINT":  {math.MinInt16, math.MaxInt16},
+	"INTEGER":   {math.MinInt32, math.MaxInt32},
+	"INT":       {math.MinInt32, math.MaxInt32},
+	"BIGINT":    {math.MinInt64, math.MaxInt64},
+	"HUGEINT":   {math.MinInt64, math.MaxInt64},
+	"UTINYINT":  {0, math.MaxUint8},
+	"USMALLINT": {0, math.MaxUint16},
+	"UINTEGER":  {0, math.MaxUint32},
+	"UBIGINT":   {0, math.MaxInt64},
+	"UHUGEINT":  {0, math.MaxInt64},
+}
+
+// coerceValue converts a decoded JSON value to a Go value matching the SQL
+// column type, so that e.g. "12" can be written to a BIGINT column and 12 to
+// a VARCHAR column.
+func coerceValue(col ColumnInfo, val any) (any, error) {
+	if val == nil {
+		return nil, nil
+	}
+
+	colType := strings.ToUpper(col.Type)
+	if i := strings.IndexByte(colType, '('); i >= 0 {
+		colType = colType[:i]
+	}
+
+	invalid := func() (any, error) {
+		return nil, fmt.Errorf("%w: value %v is not valid for column %q of type %s", ErrInvalidInput, val, col.Name, col.Type)
+	}
+
+	switch colType {
+	case "TINYINT", "SMALLINT", "INTEGER", "INT", "BIGINT", "HUGEINT",
+		"UTINYINT", "USMALLINT", "UINTEGER", "UBIGINT", "UHUGEINT":
+		var n int64
+		switch v := val.(type) {
+		case float64:
+			// float64(math.MaxInt64) rounds up to 2^63, which int64 cannot hold.
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return invalid()
+			}
+			n = int64(v)
+		case string:
+			parsed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return invalid()
+			}
+			n = parsed
+		case bool:
+			if v {
+				n = 1
+			}
+		default:
+			return invalid()
+		}
+		bounds := integerRanges[colType]
+		if n < bounds[0] || n > bounds[1] {
+			return invalid()
+		}
+		return n, nil
+	case "FLOAT", "REAL", "DOUBLE", "DECIMAL", "NUMERIC":
+		switch v := val.(type) {
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return invalid()
+			}
+			return f, nil
+		}
+	case "BOOLEAN", "BOOL":
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case float64:
+			return v != 0, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return invalid()
+			}
+			return b, nil
+		}
+	case "DATE":
+		if v, ok := val.(string); ok {
+			if _, err := time.Parse(time.DateOnly, v); err != nil {
+				return invalid()
+			}
+			return v, nil
+		}
+	case "TIMESTAMP", "TIMESTAMP WITH TIME ZONE", "TIMESTAMPTZ", "TIME":
+		if v, ok := val.(string); ok {
+			return v, nil
+		}
+	default:
+		switch v := val.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	}
+
+	return invalid()
+}
+
+func (db *DB) writableTable(ctx context.Context, id string) (*CSVTable, *sql.DB, []ColumnInfo, error) {
+	csvTable, err := db.ownedCSVTable(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !csvTable.Writable {
+		return nil, nil, nil, ErrNotWritable
+	}
+
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tableCols, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Row ids are assigned by the database and cannot be written.
+	columns := make([]ColumnInfo, 0, len(tableCols))
+	for _, col := range tableCols {
+		if col.Name != RowIDColumn {
+			columns = append(columns, col)
+		}
+	}
+
+	return csvTable, conn, columns, nil
+}
+
+// InsertRows appends rows to a writable dataset. Each row is either a
+// map keyed by column name or a slice of values in column order.
+func (db *DB) InsertRows(ctx context.Context, id string, rows []any) (int, error) {
+	csvTable, conn, columns, err := db.writableTable(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.checkQuota(ctx, 0); err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	for i, row := range rows {
+		var names []string
+		var values []any
+
+		switch r := row.(type) {
+		case map[string]any:
+			for name, val := range r {
+				col, ok := findColumn(columns, name)
+				if !ok {
+					err = fmt.Errorf("%w: row %d: unknown column %q", ErrInvalidInput, i, name)
+					return 0, err
+				}
+				coerced, cErr := coerceValue(col, val)
+				if cErr != nil {
+					err = fmt.Errorf("row %d: %w", i, cErr)
+					return 0, err
+				}
+				names = append(names, col.Name)
+				values = append(values, coerced)
+			}
+		case []any:
+			if len(r) != len(columns) {
+				err = fmt.Errorf("%w: row %d: expected %d values, got %d", ErrInvalidInput, i, len(columns), len(r))
+				return 0, err
+			}
+			for j, col := range columns {
+				coerced, cErr := coerceValue(col, r[j])
+				if cErr != nil {
+					err = fmt.Errorf("row %d: %w", i, cErr)
+					return 0, err
+				}
+				names = append(names, col.Name)
+				values = append(values, coerced)
+			}
+		default:
+			err = fmt.Errorf("%w: row %d must be an object or an array", ErrInvalidInput, i)
+			return 0, err
+		}
+
+		if len(names) == 0 {
+			err = fmt.Errorf("%w: row %d has no values", ErrInvalidInput, i)
+			return 0, err
+		}
+
+		quoted := make([]string, len(names))
+		for j, name := range names {
+			quoted[j] = quoteIdent(name)
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			csvTable.TableName,
+			strings.Join(quoted, ", "),
+			strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+
+		if _, err = tx.ExecContext(ctx, query, values...); err != nil {
+			err = keyConflict(fmt.Errorf("failed to insert row %d: %w", i, err))
+			return 0, err
+		}
+	}
+
+	if err = db.datasetChanged(ctx, tx, csvTable); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(rows), nil
+}
+
+// rowPredicate returns the condition and argument identifying a single row,
+// by the dataset's key column when declared and by row id otherwise.
+func rowPredicate(csvTable *CSVTable, columns []ColumnInfo, rowKey string) (string, any, error) {
+	if csvTable.KeyColumn == "" {
+		rowID, err := strconv.ParseInt(rowKey, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: invalid row id %q", ErrInvalidInput, rowKey)
+		}
+		return RowIDColumn + " = ?", rowID, nil
+	}
+
+	col, ok := findColumn(columns, csvTable.KeyColumn)
+	if !ok {
+		return "", nil, fmt.Errorf("key column %q not found", csvTable.KeyColumn)
+	}
+
+	value, err := coerceValue(col, rowKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return quoteIdent(col.Name) + " = ?", value, nil
+}
+
+// GetRow returns a single row identified by key value or row id, shaped
+// according to format.
+func (db *DB) GetRow(ctx context.Context, id string, rowKey string, format string) ([]string, any, error) {
+	csvTable, err := db.GetCSVTable(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	where, arg, err := rowPredicate(csvTable, columns, rowKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", csvTable.TableName, where), arg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query row: %w", err)
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, nil, fmt.Errorf("error iterating rows: %w", err)
+		}
+		return nil, nil, fmt.Errorf("row %s %w", rowKey, ErrNotFound)
+	}
+
+	values := make([]any, len(names))
+	scanArgs := make([]any, len(names))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	if err := rows.Scan(scanArgs...); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	transform, ok := transformFuncs[format]
+	if !ok {
+		transform = transformObject
+	}
+
+	return names, transform(names, values), nil
+}
+
+// UpdateRow sets the given column values on a single row of a writable dataset.
+func (db *DB) UpdateRow(ctx context.Context, id string, rowKey string, values map[string]any) (_ int, err error) {
+	csvTable, conn, columns, err := db.writableTable(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w: no columns to update", ErrInvalidInput)
+	}
+
+	where, arg, err := rowPredicate(csvTable, columns, rowKey)
+	if err != nil {
+		return 0, err
+	}
+
+	var assignments []string
+	var args []any
+
+	for name, val := range values {
+		col, ok := findColumn(columns, name)
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown column %q", ErrInvalidInput, name)
+		}
+		coerced, err := coerceValue(col, val)
+		if err != nil {
+			return 0, err
+		}
+		assignments = append(assignments, quoteIdent(col.Name)+" = ?")
+		args = append(args, coerced)
+	}
+	args = append(args, arg)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", csvTable.TableName, strings.Join(assignments, ", "), where)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, keyConflict(fmt.Errorf("failed to update row: %w", err))
+	}
+
+	affected, err := rowsAffected(result, rowKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = db.datasetChanged(ctx, tx, csvTable); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return affected, nil
+}
+
+// DeleteRow removes a single row from a writable dataset.
+func (db *DB) DeleteRow(ctx context.Context, id string, rowKey string) (_ int, err error) {
+	csvTable, conn, columns, err := db.writableTable(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	where, arg, err := rowPredicate(csvTable, columns, rowKey)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", csvTable.TableName, where), arg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete row: %w", err)
+	}
+
+	affected, err := rowsAffected(result, rowKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = db.datasetChanged(ctx, tx, csvTable); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return affected, nil
+}
+
+func rowsAffected(result sql.Result, rowKey string) (int, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return 0, fmt.Errorf("row %s %w", rowKey, ErrNotFound)
+	}
+
+	return int(affected), nil
+}
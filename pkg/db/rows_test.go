@@ -0,0 +1,60 @@
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCoerceValueIntegerRanges(t *testing.T) {
+	tests := []struct {
+		colType string
+		val     any
+		want    any
+	}{
+		{"TINYINT", 127.0, int64(127)},
+		{"TINYINT", 128.0, nil},
+		{"TINYINT", -128.0, int64(-128)},
+		{"TINYINT", "-129", nil},
+		{"UTINYINT", 255.0, int64(255)},
+		{"UTINYINT", 256.0, nil},
+		{"UTINYINT", -1.0, nil},
+		{"SMALLINT", "32767", int64(32767)},
+		{"SMALLINT", "-32769", nil},
+		{"USMALLINT", "65535", int64(65535)},
+		{"USMALLINT", "-1", nil},
+		{"INTEGER", 2147483647.0, int64(2147483647)},
+		{"INTEGER", 2147483648.0, nil},
+		{"INTEGER", -2147483648.0, int64(-2147483648)},
+		{"UINTEGER", 4294967295.0, int64(4294967295)},
+		{"UINTEGER", 4294967296.0, nil},
+		{"UINTEGER", "-1", nil},
+		{"BIGINT", "9223372036854775807", int64(9223372036854775807)},
+		{"BIGINT", "9223372036854775808", nil},
+		{"BIGINT", "-9223372036854775808", int64(-9223372036854775808)},
+		{"BIGINT", 9223372036854775807.0, nil},
+		{"BIGINT", -9223372036854775808.0, int64(-9223372036854775808)},
+		{"BIGINT", 1e300, nil},
+		{"BIGINT", 1.5, nil},
+		{"UBIGINT", -1.0, nil},
+		{"UBIGINT", "0", int64(0)},
+		{"UHUGEINT", "-5", nil},
+		{"HUGEINT", "-5", int64(-5)},
+		{"UTINYINT", true, int64(1)},
+	}
+
+	for _, tt := range tests {
+		col := ColumnInfo{Name: "n", Type: tt.colType}
+		got, err := coerceValue(col, tt.val)
+
+		if tt.want == nil {
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("coerceValue(%s, %v) = %v, %v, want ErrInvalidInput", tt.colType, tt.val, got, err)
+			}
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Errorf("coerceValue(%s, %v) = %v, %v, want %v", tt.colType, tt.val, got, err, tt.want)
+		}
+	}
+}
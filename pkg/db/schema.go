@@ -0,0 +1,405 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const castFailureSample = 20
+
+// castTypes are the column types a column can be cast to.
+var castTypes = regexp.MustCompile(`^(BOOLEAN|TINYINT|SMALLINT|INTEGER|BIGINT|HUGEINT|` +
+	`UTINYINT|USMALLINT|UINTEGER|UBIGINT|FLOAT|REAL|DOUBLE|DECIMAL\(\d{1,2}, ?\d{1,2}\)|` +
+	`VARCHAR|DATE|TIME|TIMESTAMP|TIMESTAMPTZ|UUID)$`)
+
+// SchemaChange renames a column and/or casts it to another type.
+type SchemaChange struct {
+	Column string
+	Rename string
+	Type   string
+}
+
+// CastFailure reports the rows of a column whose values cannot be cast to
+// the requested type. Rows holds up to castFailureSample of them.
+type CastFailure struct {
+	Column string
+	Type   string
+	Count  int64
+	Rows   []CastFailureRow
+}
+
+type CastFailureRow struct {
+	RowID int64
+	Value any
+}
+
+// CastError is returned by AlterSchema when values fail to cast. Nothing is
+// changed in that case.
+type CastError struct {
+	Failures []CastFailure
+}
+
+func (e *CastError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("%q has %d values that cannot be cast to %s", f.Column, f.Count, f.Type)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (e *CastError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// GetSchema returns the columns of a dataset.
+func (db *DB) GetSchema(ctx context.Context, id string) (*CSVTable, []ColumnInfo, error) {
+	csvTable, err := db.GetCSVTable(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns, err := tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return csvTable, columns, nil
+}
+
+// AlterSchema renames columns and casts them to other types. Casts are
+// checked before anything is changed and a *CastError lists the values that
+// cannot be converted, instead of replacing them with nulls. SQLite cannot
+// change column types, so only renames are supported on persisted datasets.
+func (db *DB) AlterSchema(ctx context.Context, id string, changes []SchemaChange) (*CSVTable, []ColumnInfo, error) {
+	if len(changes) == 0 {
+		return nil, nil, fmt.Errorf("%w: no schema changes", ErrInvalidInput)
+	}
+
+	csvTable, columns, err := db.GetSchema(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !canModify(ctx, csvTable) {
+		return nil, nil, fmt.Errorf("%w: dataset %s belongs to another owner", ErrNotWritable, id)
+	}
+
+	if csvTable.isVirtual() {
+		return nil, nil, fmt.Errorf("%w: columns of virtual views cannot be changed", ErrInvalidInput)
+	}
+
+	conn, err := db.tableConn(ctx, csvTable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		names[col.Name] = true
+	}
+
+	renames := make(map[string]string)
+	var statements []string
+
+	for i, change := range changes {
+		col, ok := findColumn(columns, change.Column)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown column %q", ErrInvalidInput, change.Column)
+		}
+		if col.Name == RowIDColumn {
+			return nil, nil, fmt.Errorf("%w: column %s cannot be changed", ErrInvalidInput, RowIDColumn)
+		}
+		if change.Rename == "" && change.Type == "" {
+			return nil, nil, fmt.Errorf("%w: change %d has no rename or type", ErrInvalidInput, i)
+		}
+		if _, dup := renames[col.Name]; dup {
+			return nil, nil, fmt.Errorf("%w: column %q changed more than once", ErrInvalidInput, col.Name)
+		}
+
+		if change.Type != "" {
+			if csvTable.Persisted {
+				return nil, nil, fmt.Errorf("%w: column types of persisted datasets cannot be changed", ErrInvalidInput)
+			}
+			colType := strings.ToUpper(strings.TrimSpace(change.Type))
+			if !castTypes.MatchString(colType) {
+				return nil, nil, fmt.Errorf("%w: unsupported column type %q", ErrInvalidInput, change.Type)
+			}
+			changes[i].Type = colType
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DATA TYPE %s",
+				csvTable.TableName, quoteIdent(col.Name), colType))
+		}
+
+		renames[col.Name] = col.Name
+		if change.Rename != "" && change.Rename != col.Name {
+			if change.Rename == RowIDColumn || names[change.Rename] {
+				return nil, nil, fmt.Errorf("%w: column %q already exists", ErrInvalidInput, change.Rename)
+			}
+			delete(names, col.Name)
+			names[change.Rename] = true
+			renames[col.Name] = change.Rename
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+				csvTable.TableName, quoteIdent(col.Name), quoteIdent(change.Rename)))
+		}
+	}
+
+	if err := checkCasts(ctx, conn, csvTable.TableName, changes); err != nil {
+		return nil, nil, err
+	}
+
+	updated := renamedTable(csvTable, renames)
+
+	if csvTable.Persisted {
+		err = db.alterTursoSchema(ctx, csvTable, updated, statements)
+	} else {
+		err = db.alterDuckDBSchema(ctx, conn, csvTable, updated, statements)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns, err = tableColumns(ctx, conn, csvTable.TableName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, columns, nil
+}
+
+// checkCasts finds the non-null values that would not survive the type
+// changes.
+func checkCasts(ctx context.Context, conn queryer, table string, changes []SchemaChange) error {
+	var failures []CastFailure
+
+	for _, change := range changes {
+		if change.Type == "" {
+			continue
+		}
+
+		col := quoteIdent(change.Column)
+		failing := fmt.Sprintf("%s IS NOT NULL AND TRY_CAST(%s AS %s) IS NULL", col, col, change.Type)
+
+		var count int64
+		if err := conn.QueryRowContext(ctx,
+			fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, failing)).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check cast of %q: %w", change.Column, err)
+		}
+		if count == 0 {
+			continue
+		}
+
+		rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s ORDER BY %s LIMIT %d",
+			RowIDColumn, col, table, failing, RowIDColumn, castFailureSample))
+		if err != nil {
+			return fmt.Errorf("failed to check cast of %q: %w", change.Column, err)
+		}
+
+		failure := CastFailure{Column: change.Column, Type: change.Type, Count: count}
+		for rows.Next() {
+			var row CastFailureRow
+			if err := rows.Scan(&row.RowID, &row.Value); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan cast failure: %w", err)
+			}
+			row.Value = stringifyValue(row.Value)
+			failure.Rows = append(failure.Rows, row)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("error iterating cast failures: %w", err)
+		}
+
+		failures = append(failures, failure)
+	}
+
+	if len(failures) > 0 {
+		return &CastError{Failures: failures}
+	}
+
+	return nil
+}
+
+// renamedTable returns a copy of csvTable with the column references in its
+// metadata renamed.
+func renamedTable(csvTable *CSVTable, renames map[string]string) *CSVTable {
+	rename := func(name string) string {
+		if to, ok := renames[name]; ok {
+			return to
+		}
+		return name
+	}
+	renameAll := func(names []string) []string {
+		if names == nil {
+			return nil
+		}
+		out := make([]string, len(names))
+		for i, name := range names {
+			out[i] = rename(name)
+		}
+		return out
+	}
+
+	updated := *csvTable
+	if updated.KeyColumn != "" {
+		updated.KeyColumn = rename(updated.KeyColumn)
+	}
+	updated.IndexedColumns = renameAll(csvTable.IndexedColumns)
+	updated.SearchColumns = renameAll(csvTable.SearchColumns)
+	updated.SuggestedFacets = renameAll(csvTable.SuggestedFacets)
+	if csvTable.ColumnLabels != nil {
+		updated.ColumnLabels = make(map[string]string, len(csvTable.ColumnLabels))
+		for name, label := range csvTable.ColumnLabels {
+			updated.ColumnLabels[rename(name)] = label
+		}
+	}
+	return &updated
+}
+
+// alterDuckDBSchema runs the ALTER statements in a transaction and stores the
+// column references of updated. Indexes are dropped up front, DuckDB refuses
+// to alter indexed tables, and recreated on the renamed columns in the
+// transaction, or restored on failure.
+func (db *DB) alterDuckDBSchema(ctx context.Context, conn *sql.DB, csvTable, updated *CSVTable, statements []string) (err error) {
+	if err = dropIndexes(ctx, conn, csvTable, csvTable.TableName); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if idxErr := createIndexes(context.Background(), conn, csvTable, csvTable.TableName); idxErr != nil {
+				slog.ErrorContext(ctx, "Error restoring indexes", "error", idxErr)
+			}
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to alter dataset columns: %w", err)
+		}
+	}
+
+	if err = createIndexes(ctx, tx, updated, csvTable.TableName); err != nil {
+		return err
+	}
+
+	if err = db.datasetChanged(ctx, tx, updated); err != nil {
+		return err
+	}
+
+	// The metadata is stored before committing, so a failure leaves the
+	// table unchanged, and restored if the commit fails.
+	if err = updateTableColumns(ctx, db.tursoConn, updated); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		if restoreErr := updateTableColumns(context.Background(), db.tursoConn, csvTable); restoreErr != nil {
+			slog.ErrorContext(ctx, "Error restoring dataset columns", "error", restoreErr)
+		}
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// alterTursoSchema renames columns of a persisted table and stores the column
+// references of updated in the same transaction. The FTS5 index refers to
+// its columns by name, so it is recreated.
+func (db *DB) alterTursoSchema(ctx context.Context, csvTable, updated *CSVTable, statements []string) (err error) {
+	tx, err := db.tursoConn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.ErrorContext(ctx, "Error rolling back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to alter dataset columns: %w", err)
+		}
+	}
+
+	if len(csvTable.SearchColumns) > 0 && !slices.Equal(csvTable.SearchColumns, updated.SearchColumns) {
+		if _, err = tx.ExecContext(ctx, "DROP TABLE "+tursoSearchTable(csvTable.TableName)); err != nil {
+			return fmt.Errorf("failed to drop full-text index: %w", err)
+		}
+		if err = createTursoSearchIndex(ctx, tx, csvTable.TableName, updated.SearchColumns); err != nil {
+			return err
+		}
+	}
+
+	if err = db.datasetChanged(ctx, tx, updated); err != nil {
+		return err
+	}
+
+	if err = updateTableColumns(ctx, tx, updated); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// updateTableColumns stores the column references of csvTable's metadata.
+func updateTableColumns(ctx context.Context, conn execer, csvTable *CSVTable) error {
+	nullJSON := func(names []string) any {
+		if names == nil {
+			return nil
+		}
+		data, _ := json.Marshal(names)
+		return string(data)
+	}
+
+	var keyColumn any
+	if csvTable.KeyColumn != "" {
+		keyColumn = csvTable.KeyColumn
+	}
+
+	var columnLabels any
+	if csvTable.ColumnLabels != nil {
+		data, _ := json.Marshal(csvTable.ColumnLabels)
+		columnLabels = string(data)
+	}
+
+	_, err := conn.ExecContext(ctx, `
+		UPDATE csv_table
+		SET key_column = ?, indexed_columns = ?, search_columns = ?, suggested_facets = ?, column_labels = ?
+		WHERE id = ?
+	`, keyColumn, nullJSON(csvTable.IndexedColumns), nullJSON(csvTable.SearchColumns),
+		nullJSON(csvTable.SuggestedFacets), columnLabels, csvTable.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update dataset columns: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ScoreColumn holds the relevance of each row matching a full-text search,
+// higher is more relevant.
+const ScoreColumn = "_score"
+
+const snippetWords = 12
+
+// searchableColumns returns the columns to index for full-text search: the
+// requested columns, or all text columns when none are requested.
+func searchableColumns(columns []ColumnInfo, requested []string) ([]string, error) {
+	if len(requested) > 0 {
+		names := make([]string, 0, len(requested))
+		for _, name := range requested {
+			col, ok := findColumn(columns, name)
+			if !ok || col.Name == RowIDColumn {
+				return nil, fmt.Errorf("%w: unknown search column %q", ErrInvalidInput, name)
+			}
+			names = append(names, col.Name)
+		}
+		return names, nil
+	}
+
+	var names []string
+	for _, col := range columns {
+		if strings.EqualFold(col.Type, "VARCHAR") {
+			names = append(names, col.Name)
+		}
+	}
+	return names, nil
+}
+
+func duckDBSearchSchema(table string) string {
+	return "fts_main_" + table
+}
+
+func tursoSearchTable(table string) string {
+	return table + "_fts"
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// installFTS installs DuckDB's full-text search extension, downloading it
+// unless it is already in DuckDB's extension directory. It runs once at
+// startup, datasets only load the installed extension.
+func installFTS(ctx context.Context) error {
+	conn, err := sql.Open("duckdb", "")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, stmt := range []string{"INSTALL fts", "LOAD fts"} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) loadFTS(ctx context.Context, conn execer) error {
+	if db.ftsErr != nil {
+		return db.ftsErr
+	}
+	if _, err := conn.ExecContext(ctx, "LOAD fts"); err != nil {
+		return fmt.Errorf("failed to load full-text search extension: %w", err)
+	}
+	return nil
+}
+
+// createSearchIndex builds a DuckDB full-text index over the search columns,
+// replacing an existing one. DuckDB indexes are not updated by writes, see
+// refreshSearchIndex.
+func (db *DB) createSearchIndex(ctx context.Context, conn execer, table string, columns []string) error {
+	if err := db.loadFTS(ctx, conn); err != nil {
+		return err
+	}
+
+	args := []string{quoteString(table), quoteString(RowIDColumn)}
+	for _, col := range columns {
+		args = append(args, quoteString(col))
+	}
+
+	query := fmt.Sprintf("PRAGMA create_fts_index(%s, overwrite = 1)", strings.Join(args, ", "))
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create full-text index: %w", err)
+	}
+
+	return nil
+}
+
+// createTursoSearchIndex creates an FTS5 table over the search columns of a
+// persisted table and fills it from the table's rows.
+func createTursoSearchIndex(ctx context.Context, conn execer, table string, columns []string) error {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+
+	ftsTable := tursoSearchTable(table)
+
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(
+		"CREATE VIRTUAL TABLE %s USING fts5(%s, content=%s, content_rowid=%s)",
+		ftsTable, strings.Join(quoted, ", "), quoteString(table), quoteString(RowIDColumn)))
+	if err != nil {
+		return fmt.Errorf("failed to create full-text index: %w", err)
+	}
+
+	return rebuildTursoSearchIndex(ctx, conn, table)
+}
+
+func rebuildTursoSearchIndex(ctx context.Context, conn execer, table string) error {
+	ftsTable := tursoSearchTable(table)
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", ftsTable, ftsTable)); err != nil {
+		return fmt.Errorf("failed to build full-text index: %w", err)
+	}
+	return nil
+}
+
+// searchIndex holds the dataset version a full-text index was built at. mu
+// keeps concurrent searches from rebuilding the same index.
+type searchIndex struct {
+	mu      sync.Mutex
+	version int64
+}
+
+func (db *DB) searchIndex(id string) *searchIndex {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	idx, ok := db.searchIndexes[id]
+	if !ok {
+		idx = &searchIndex{}
+		db.searchIndexes[id] = idx
+	}
+	return idx
+}
+
+// searchIndexBuilt records that the full-text index of a dataset is current
+// at version.
+func (db *DB) searchIndexBuilt(id string, version int64) {
+	idx := db.searchIndex(id)
+	idx.mu.Lock()
+	idx.version = version
+	idx.mu.Unlock()
+}
+
+// refreshSearchIndex rebuilds the full-text index of a dataset when its rows
+// changed since the index was built. Writes leave the index alone, so a burst
+// of writes costs a single rebuild, on the next search.
+func (db *DB) refreshSearchIndex(ctx context.Context, conn execer, csvTable *CSVTable) error {
+	idx := db.searchIndex(csvTable.ID)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.version >= csvTable.Version {
+		return nil
+	}
+
+	var err error
+	if csvTable.Persisted {
+		err = rebuildTursoSearchIndex(ctx, conn, csvTable.TableName)
+	} else {
+		err = db.createSearchIndex(ctx, conn, csvTable.TableName, csvTable.SearchColumns)
+	}
+	if err != nil {
+		return err
+	}
+
+	idx.version = csvTable.Version
+	return nil
+}
+
+// searchTerms splits a search query into lower case words.
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchSource returns a subquery of the rows of a dataset matching a full
+// text search, with their relevance in ScoreColumn.
+func (db *DB) searchSource(ctx context.Context, conn execer, csvTable *CSVTable, q string) (string, []any, error) {
+	if len(csvTable.SearchColumns) == 0 {
+		return "", nil, fmt.Errorf("%w: dataset has no full-text index", ErrInvalidInput)
+	}
+
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return "", nil, fmt.Errorf("%w: search query has no words", ErrInvalidInput)
+	}
+
+	if err := db.refreshSearchIndex(ctx, conn, csvTable); err != nil {
+		return "", nil, err
+	}
+
+	table := csvTable.TableName
+
+	if csvTable.Persisted {
+		// Quoting each term keeps FTS5 query syntax out of user input.
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + term + `"`
+		}
+
+		ftsTable := tursoSearchTable(table)
+		source := fmt.Sprintf(
+			"(SELECT %s.*, -bm25(%s) AS %s FROM %s JOIN %s ON %s.rowid = %s.%s WHERE %s MATCH ?)",
+			table, ftsTable, ScoreColumn, table, ftsTable, ftsTable, table, RowIDColumn, ftsTable)
+		return source, []any{strings.Join(quoted, " OR ")}, nil
+	}
+
+	if err := db.loadFTS(ctx, conn); err != nil {
+		return "", nil, err
+	}
+
+	source := fmt.Sprintf(
+		"(SELECT * FROM (SELECT *, %s.match_bm25(%s, ?) AS %s FROM %s) WHERE %s IS NOT NULL)",
+		duckDBSearchSchema(table), RowIDColumn, ScoreColumn, table, ScoreColumn)
+	return source, []any{strings.Join(terms, " ")}, nil
+}
+
+// snippet returns an excerpt of value around the first word starting with
+// one of the search terms, with matching words wrapped in <mark> tags. The
+// text is HTML escaped. It returns false when no word matches.
+func snippet(value string, terms []string) (string, bool) {
+	words := strings.Fields(value)
+
+	matches := func(word string) bool {
+		lower := strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}))
+		for _, term := range terms {
+			if strings.HasPrefix(lower, term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	first := -1
+	for i, word := range words {
+		if matches(word) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start := max(first-snippetWords/2, 0)
+	end := min(start+snippetWords, len(words))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteByte(' ')
+		}
+		if matches(words[i]) {
+			b.WriteString("<mark>" + html.EscapeString(words[i]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(words[i]))
+		}
+	}
+	if end < len(words) {
+		b.WriteString(" …")
+	}
+
+	return b.String(), true
+}
+
+// rowSnippets returns the highlighted snippets of the search columns of a
+// row that match the search terms, keyed by column name.
+func rowSnippets(columns []string, values []any, terms []string) map[string]string {
+	snippets := make(map[string]string)
+	for i, col := range columns {
+		var text string
+		switch v := values[i].(type) {
+		case string:
+			text = v
+		case []byte:
+			text = string(v)
+		default:
+			continue
+		}
+		if s, ok := snippet(text, terms); ok {
+			snippets[col] = s
+		}
+	}
+	return snippets
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sortKey is one ORDER BY term. Rows are always ordered by a list of sort
+// keys ending in the row id, which makes the order total and lets a cursor
+// resume after the last row of a page.
+type sortKey struct {
+	Column     string
+	Desc       bool
+	NullsFirst bool
+}
+
+func (k sortKey) String() string {
+	dir := "asc"
+	if k.Desc {
+		dir = "desc"
+	}
+	nulls := "last"
+	if k.NullsFirst {
+		nulls = "first"
+	}
+	return k.Column + ":" + dir + ":" + nulls
+}
+
+// withRowID appends the row id as final tie breaker unless already sorted by it.
+func withRowID(keys []sortKey) []sortKey {
+	for _, k := range keys {
+		if k.Column == RowIDColumn {
+			return keys
+		}
+	}
+	return append(keys, sortKey{Column: RowIDColumn})
+}
+
+func orderByClause(keys []sortKey) string {
+	terms := make([]string, len(keys))
+	for i, k := range keys {
+		term := quoteIdent(k.Column)
+		if k.Desc {
+			term += " DESC"
+		} else {
+			term += " ASC"
+		}
+		if k.NullsFirst {
+			term += " NULLS FIRST"
+		} else {
+			term += " NULLS LAST"
+		}
+		terms[i] = term
+	}
+	return strings.Join(terms, ", ")
+}
+
+// parseSort parses a comma separated list of sort keys such as
+// "Category,-Price:nulls_first,Date". A leading "-" sorts a key descending
+// and an optional ":nulls_first" or ":nulls_last" suffix places nulls, which
+// sort last by default. Columns are validated against the table columns.
+func parseSort(sort string, columns []ColumnInfo) ([]sortKey, error) {
+	var keys []sortKey
+	seen := make(map[string]bool)
+
+	for _, term := range strings.Split(sort, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var key sortKey
+
+		if rest, ok := strings.CutPrefix(term, "-"); ok {
+			key.Desc = true
+			term = rest
+		} else {
+			term = strings.TrimPrefix(term, "+")
+		}
+
+		if i := strings.LastIndex(term, ":"); i >= 0 {
+			switch strings.ToLower(term[i+1:]) {
+			case "nulls_first":
+				key.NullsFirst = true
+				term = term[:i]
+			case "nulls_last":
+				term = term[:i]
+			}
+		}
+
+		col, ok := findColumn(columns, term)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown sort column %q", ErrInvalidInput, term)
+		}
+		if seen[col.Name] {
+			return nil, fmt.Errorf("%w: duplicate sort column %q", ErrInvalidInput, col.Name)
+		}
+		seen[col.Name] = true
+
+		key.Column = col.Name
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// querySortKeys returns the sort keys of a query, from Sort or the single
+// SortColumn and SortOrder pair, ending in the row id.
+func querySortKeys(params *QueryCSV, columns []ColumnInfo) ([]sortKey, error) {
+	if params.Sort != "" {
+		if params.SortColumn != "" {
+			return nil, fmt.Errorf("%w: sort cannot be combined with sortColumn", ErrInvalidInput)
+		}
+
+		keys, err := parseSort(params.Sort, columns)
+		if err != nil {
+			return nil, err
+		}
+		return withRowID(keys), nil
+	}
+
+	// Search results are ranked by relevance unless sorted otherwise.
+	if params.Search != "" && params.SortColumn == "" {
+		return withRowID([]sortKey{{Column: ScoreColumn, Desc: true}}), nil
+	}
+
+	key := sortKey{Column: RowIDColumn, Desc: params.SortOrder == "DESC"}
+	if params.SortColumn != "" {
+		col, ok := findColumn(columns, params.SortColumn)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown sort column %q", ErrInvalidInput, params.SortColumn)
+		}
+		key.Column = col.Name
+	}
+
+	return withRowID([]sortKey{key}), nil
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+)
+
+// OpenDuckDBConnections returns the number of dataset files held open.
+func (db *DB) OpenDuckDBConnections() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return len(db.duckDBMap)
+}
+
+// DataDirSize returns the size of the files in the data directory.
+func (db *DB) DataDirSize() int64 {
+	var size int64
+	err := filepath.WalkDir(db.dataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Error measuring data directory", "error", err)
+	}
+	return size
+}
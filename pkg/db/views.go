@@ -0,0 +1,409 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/logging"
+	"github.com/google/uuid"
+)
+
+var joinTypes = map[string]string{
+	"inner": "INNER JOIN",
+	"left":  "LEFT JOIN",
+	"right": "RIGHT JOIN",
+	"full":  "FULL OUTER JOIN",
+}
+
+// ViewDefinition joins datasets into a view. The first dataset is the base
+// and each following dataset is joined to the datasets before it.
+type ViewDefinition struct {
+	Name         string        `json:"name"`
+	Materialized bool          `json:"materialized"`
+	Datasets     []ViewDataset `json:"datasets"`
+}
+
+// ViewDataset is a dataset in a view. Alias prefixes its columns whose names
+// are already used by an earlier dataset and defaults to its file name.
+type ViewDataset struct {
+	ID    string     `json:"id"`
+	Alias string     `json:"alias"`
+	Join  string     `json:"join,omitempty"`
+	On    []JoinKeys `json:"on,omitempty"`
+}
+
+// JoinKeys matches the Left column of an earlier dataset, written as column
+// or alias.column, with the Right column of the joined dataset.
+type JoinKeys struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// isVirtual reports whether the dataset is a view evaluated on every query
+// instead of a table of its own.
+func (t *CSVTable) isVirtual() bool {
+	return t.View != nil && !t.View.Materialized
+}
+
+// viewSchema is the name of the database the DuckDB file of a source dataset
+// of a view is attached as while its rows are joined. Datasets joined more
+// than once are attached once.
+func viewSchema(id string) string {
+	return "src_" + strings.ReplaceAll(id, "-", "_")
+}
+
+// CreateView defines a view joining datasets stored in DuckDB. Materialized
+// views copy the joined rows into a DuckDB file of their own, virtual views
+// read the DuckDB files of the datasets and join them on every query. Either
+// way the view gets a dataset ID and is read only.
+func (db *DB) CreateView(ctx context.Context, def ViewDefinition) (*CSVTable, error) {
+	if len(def.Datasets) < 2 {
+		return nil, fmt.Errorf("%w: a view joins at least two datasets", ErrInvalidInput)
+	}
+
+	if err := db.checkQuota(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	sources := make([]*CSVTable, len(def.Datasets))
+	for i, ds := range def.Datasets {
+		source, err := db.GetCSVTable(ctx, ds.ID)
+		if err != nil {
+			return nil, err
+		}
+		if source.Persisted || source.isVirtual() {
+			return nil, fmt.Errorf("%w: dataset %s is not stored in DuckDB and cannot be joined", ErrInvalidInput, ds.ID)
+		}
+		sources[i] = source
+	}
+
+	query, err := db.viewQuery(ctx, &def, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	ctx = logging.With(ctx, "dataset_id", id)
+	csvTable := &CSVTable{
+		ID:         id,
+		Filename:   def.Name,
+		TableName:  "csv_data",
+		CreatedAt:  time.Now().UTC(),
+		Version:    1,
+		View:       &def,
+		Owner:      contextOwner(ctx).String,
+		Visibility: VisibilityPrivate,
+	}
+	if csvTable.Filename == "" {
+		csvTable.Filename = "view"
+	}
+
+	var conn *sql.DB
+	if def.Materialized {
+		conn, err = db.materializeView(ctx, csvTable, sources, query)
+		if err == nil {
+			err = db.checkStorageQuota(ctx, id)
+		}
+	} else {
+		conn, err = db.viewConn(ctx, csvTable)
+	}
+	if err != nil {
+		db.removeDuckDB(id)
+		return nil, err
+	}
+
+	suggested, err := suggestFacets(ctx, conn, csvTable.TableName)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error suggesting facets", "error", err)
+	}
+	csvTable.SuggestedFacets = suggested
+	suggestedFacets, _ := json.Marshal(suggested)
+	viewDefinition, _ := json.Marshal(def)
+
+	_, err = db.tursoConn.ExecContext(ctx, `
+		INSERT INTO csv_table (id, filename, table_name, created_at, persisted, writable, suggested_facets, view_definition, owner)
+		VALUES (?, ?, ?, ?, 0, 0, ?, ?, ?)
+	`, id, csvTable.Filename, csvTable.TableName, csvTable.CreatedAt,
+		sql.NullString{String: string(suggestedFacets), Valid: suggested != nil}, string(viewDefinition),
+		contextOwner(ctx))
+	if err != nil {
+		db.removeDuckDB(id)
+		return nil, fmt.Errorf("failed to store view reference: %w", err)
+	}
+
+	return csvTable, nil
+}
+
+// viewQuery validates the datasets and join keys of a view and returns the
+// query selecting its rows from the attached source datasets. Columns keep
+// their names unless an earlier dataset uses the name, then they are
+// prefixed with the dataset alias. Aliases default to the file name.
+func (db *DB) viewQuery(ctx context.Context, def *ViewDefinition, sources []*CSVTable) (string, error) {
+	aliases := make(map[string]int, len(sources))
+	columns := make([][]ColumnInfo, len(sources))
+	used := make(map[string]bool)
+
+	var selected, order []string
+	from := ""
+
+	for i, source := range sources {
+		ds := &def.Datasets[i]
+		if ds.Alias == "" {
+			ds.Alias = normalizeHeader(strings.TrimSuffix(source.Filename, filepath.Ext(source.Filename)))
+			if ds.Alias == "" {
+				ds.Alias = fmt.Sprintf("t%d", i)
+			}
+		}
+		if _, dup := aliases[ds.Alias]; dup {
+			return "", fmt.Errorf("%w: alias %q is used for more than one dataset", ErrInvalidInput, ds.Alias)
+		}
+		aliases[ds.Alias] = i
+
+		conn, err := db.getDuckDBConnection(source.ID)
+		if err != nil {
+			return "", err
+		}
+		columns[i], err = tableColumns(ctx, conn, source.TableName)
+		if err != nil {
+			return "", err
+		}
+
+		table := fmt.Sprintf("t%d", i)
+		for _, col := range columns[i] {
+			if col.Name == RowIDColumn {
+				continue
+			}
+			name := col.Name
+			if used[name] {
+				name = ds.Alias + "_" + col.Name
+				if used[name] {
+					return "", fmt.Errorf("%w: column %q of %s is used by more than one dataset", ErrInvalidInput, col.Name, ds.Alias)
+				}
+			}
+			used[name] = true
+			selected = append(selected, fmt.Sprintf("%s.%s AS %s", table, quoteIdent(col.Name), quoteIdent(name)))
+		}
+		order = append(order, table+"."+RowIDColumn)
+
+		relation := fmt.Sprintf("%s.%s AS %s", viewSchema(source.ID), source.TableName, table)
+		if i == 0 {
+			if ds.Join != "" || len(ds.On) > 0 {
+				return "", fmt.Errorf("%w: the first dataset of a view cannot have a join", ErrInvalidInput)
+			}
+			from = relation
+			continue
+		}
+
+		if ds.Join == "" {
+			ds.Join = "inner"
+		}
+		join, ok := joinTypes[ds.Join]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown join type %q", ErrInvalidInput, ds.Join)
+		}
+		if len(ds.On) == 0 {
+			return "", fmt.Errorf("%w: dataset %s has no join keys", ErrInvalidInput, ds.Alias)
+		}
+
+		conditions := make([]string, len(ds.On))
+		for j, keys := range ds.On {
+			left, err := joinColumn(def, columns, aliases, i, keys.Left)
+			if err != nil {
+				return "", err
+			}
+			right, ok := findColumn(columns[i], keys.Right)
+			if !ok || right.Name == RowIDColumn {
+				return "", fmt.Errorf("%w: unknown join column %q of %s", ErrInvalidInput, keys.Right, ds.Alias)
+			}
+			conditions[j] = fmt.Sprintf("%s = %s.%s", left, table, quoteIdent(right.Name))
+		}
+
+		from += fmt.Sprintf(" %s %s ON %s", join, relation, strings.Join(conditions, " AND "))
+	}
+
+	return fmt.Sprintf("SELECT row_number() OVER (ORDER BY %s) AS %s, %s FROM %s",
+		strings.Join(order, ", "), RowIDColumn, strings.Join(selected, ", "), from), nil
+}
+
+// joinColumn resolves the left side of a join key of dataset n against the
+// datasets before it. A column without alias refers to the first of them
+// having it.
+func joinColumn(def *ViewDefinition, columns [][]ColumnInfo, aliases map[string]int, n int, name string) (string, error) {
+	if alias, column, ok := strings.Cut(name, "."); ok {
+		if i, found := aliases[alias]; found && i < n {
+			if col, ok := findColumn(columns[i], column); ok && col.Name != RowIDColumn {
+				return fmt.Sprintf("t%d.%s", i, quoteIdent(col.Name)), nil
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if col, ok := findColumn(columns[i], name); ok && col.Name != RowIDColumn {
+			return fmt.Sprintf("t%d.%s", i, quoteIdent(col.Name)), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unknown join column %q, expected a column of a dataset before %s",
+		ErrInvalidInput, name, def.Datasets[n].Alias)
+}
+
+// virtualView tracks the source datasets attached to the in-memory database
+// of a virtual view. mu serialises attaching them.
+type virtualView struct {
+	mu sync.Mutex
+	// version holds the versions of the attached source datasets.
+	version string
+}
+
+// attachSources attaches the DuckDB files of the source datasets of a view
+// read only to conn, as the databases named by viewSchema. Files attached
+// before are detached first, so conn sees the rows committed since.
+func (db *DB) attachSources(ctx context.Context, conn execer, sources []*CSVTable) error {
+	attached := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		if attached[source.ID] {
+			continue
+		}
+		attached[source.ID] = true
+
+		// DuckDB cannot replay schema changes from the write-ahead log of a
+		// file attached under another name, so they are written to the file
+		// first. This fails while rows of the dataset are being written,
+		// which only matters if the log holds schema changes.
+		sourceConn, err := db.getDuckDBConnection(source.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := sourceConn.ExecContext(ctx, "CHECKPOINT"); err != nil {
+			slog.WarnContext(ctx, "Error checkpointing dataset", "source_id", source.ID, "error", err)
+		}
+
+		schema := viewSchema(source.ID)
+		if _, err := conn.ExecContext(ctx, "DETACH DATABASE IF EXISTS "+schema); err != nil {
+			return fmt.Errorf("failed to detach dataset %s: %w", source.ID, err)
+		}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ATTACH %s AS %s (READ_ONLY)",
+			quoteString(db.getDuckDBPath(source.ID)), schema)); err != nil {
+			return fmt.Errorf("failed to attach dataset %s: %w", source.ID, err)
+		}
+	}
+	return nil
+}
+
+// materializeView copies the rows of a view into its own DuckDB file.
+func (db *DB) materializeView(ctx context.Context, csvTable *CSVTable, sources []*CSVTable, query string) (*sql.DB, error) {
+	conn, err := db.getDuckDBConnection(csvTable.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := db.attachSources(ctx, tx, sources); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s AS %s", csvTable.TableName, query)); err != nil {
+		return nil, fmt.Errorf("%w: failed to join datasets: %v", ErrInvalidInput, err)
+	}
+
+	for _, source := range sources {
+		if _, err := tx.ExecContext(ctx, "DETACH DATABASE IF EXISTS "+viewSchema(source.ID)); err != nil {
+			return nil, fmt.Errorf("failed to detach dataset %s: %w", source.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := createRowIDSequence(ctx, conn, csvTable.TableName); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// viewConn returns the in-memory DuckDB database evaluating a virtual view.
+// The source datasets are attached again when their version changed since
+// the last query, so the view sees their current rows. They are replaced in
+// a transaction, queries running meanwhile see the rows attached before.
+func (db *DB) viewConn(ctx context.Context, csvTable *CSVTable) (*sql.DB, error) {
+	versions := make([]string, len(csvTable.View.Datasets))
+	sources := make([]*CSVTable, len(versions))
+	for i, ds := range csvTable.View.Datasets {
+		source, err := db.GetCSVTable(ctx, ds.ID)
+		if err != nil {
+			return nil, err
+		}
+		versions[i] = fmt.Sprintf("%s:%d", ds.ID, source.Version)
+		sources[i] = source
+	}
+	signature := strings.Join(versions, ",")
+
+	db.mu.Lock()
+	view, ok := db.views[csvTable.ID]
+	if !ok {
+		view = &virtualView{}
+		db.views[csvTable.ID] = view
+	}
+	db.mu.Unlock()
+
+	view.mu.Lock()
+	defer view.mu.Unlock()
+
+	db.mu.Lock()
+	conn, ok := db.duckDBMap[csvTable.ID]
+	db.mu.Unlock()
+	if ok && view.version == signature {
+		return conn, nil
+	}
+
+	if !ok {
+		var err error
+		conn, err = sql.Open("duckdb", "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to open DuckDB connection: %w", err)
+		}
+		db.mu.Lock()
+		db.duckDBMap[csvTable.ID] = conn
+		db.mu.Unlock()
+	}
+
+	query, err := db.viewQuery(ctx, csvTable.View, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := db.attachSources(ctx, tx, sources); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", csvTable.TableName, query)); err != nil {
+		return nil, fmt.Errorf("%w: failed to join datasets: %v", ErrInvalidInput, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	view.version = signature
+	return conn, nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Visibility of a dataset to callers other than its owner when API keys are
+// required. Without authentication every dataset is accessible.
+const (
+	// VisibilityPrivate datasets are only accessible to their owner and admins.
+	VisibilityPrivate = "private"
+	// VisibilityUnlisted datasets can also be read with a share link.
+	VisibilityUnlisted = "unlisted"
+	// VisibilityPublic datasets can be read by anyone.
+	VisibilityPublic = "public"
+)
+
+func parseVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return VisibilityPrivate, nil
+	case VisibilityPrivate, VisibilityUnlisted, VisibilityPublic:
+		return visibility, nil
+	}
+	return "", fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, visibility)
+}
+
+// ownedCSVTable returns a dataset the principal of ctx may change. Datasets
+// it can only read are rejected as not writable.
+func (db *DB) ownedCSVTable(ctx context.Context, id string) (*CSVTable, error) {
+	csvTable, err := db.GetCSVTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(ctx, csvTable) {
+		return nil, fmt.Errorf("%w: dataset %s belongs to another owner", ErrNotWritable, id)
+	}
+
+	return csvTable, nil
+}
+
+// SetVisibility changes who can read a dataset. Making a dataset private
+// invalidates its share links.
+func (db *DB) SetVisibility(ctx context.Context, id string, visibility string) (*CSVTable, error) {
+	if visibility == "" {
+		return nil, fmt.Errorf("%w: visibility is required", ErrInvalidInput)
+	}
+	visibility, err := parseVisibility(visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	csvTable, err := db.ownedCSVTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.tursoConn.ExecContext(ctx,
+		"UPDATE csv_table SET visibility = ? WHERE id = ?", visibility, id); err != nil {
+		return nil, fmt.Errorf("failed to update visibility: %w", err)
+	}
+
+	csvTable.Visibility = visibility
+	return csvTable, nil
+}
+
+// ShareableCSVTable returns a dataset the principal of ctx may create share
+// links for. Private datasets have to be made unlisted or public first.
+func (db *DB) ShareableCSVTable(ctx context.Context, id string) (*CSVTable, error) {
+	csvTable, err := db.ownedCSVTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if csvTable.Visibility == VisibilityPrivate {
+		return nil, fmt.Errorf("%w: dataset %s is private, make it unlisted to share it", ErrInvalidInput, id)
+	}
+
+	return csvTable, nil
+}
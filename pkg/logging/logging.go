@@ -0,0 +1,94 @@
+// Package logging sets up structured JSON logging with log/slog and carries
+// request attributes, such as the request ID, through contexts so every log
+// record of a request includes them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// LevelOff is above every level, disabling logging.
+const LevelOff = slog.Level(12)
+
+// ParseLevel parses debug, info, warn, error or off, info when empty.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "off":
+		return LevelOff, nil
+	}
+	return 0, fmt.Errorf("log level must be debug, info, warn, error or off, got %q", name)
+}
+
+// LevelName returns the name of level accepted by ParseLevel.
+func LevelName(level slog.Level) string {
+	if level >= LevelOff {
+		return "off"
+	}
+	return strings.ToLower(level.String())
+}
+
+// New returns a logger writing JSON records to w at the level of level,
+// which can be changed while the logger is in use.
+func New(w io.Writer, level *slog.LevelVar) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+type attrsKey struct{}
+
+// With returns a copy of ctx whose log records include the attributes of
+// args, given as for slog.Logger.With.
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := append(Attrs(ctx), argsToAttrs(args)...)
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// Attrs returns the attributes added to ctx with With.
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs[:len(attrs):len(attrs)]
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+// contextHandler adds the attributes of the context to each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,118 @@
+// Package metrics defines the Prometheus metrics of the server.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "csvapi"
+
+var (
+	// Requests counts HTTP requests by operation, method and status code.
+	// Requests that match no operation are counted as "other".
+	Requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by operation, method and status code.",
+	}, []string{"operation", "method", "code"})
+
+	// RequestDuration observes the time taken to handle HTTP requests.
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	// Imports counts CSV imports by status, ok or failed.
+	Imports = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "imports_total",
+		Help:      "CSV imports by status.",
+	}, []string{"status"})
+
+	ImportBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "import_bytes_total",
+		Help:      "Bytes of CSV data imported.",
+	})
+
+	ImportRows = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "import_rows_total",
+		Help:      "Rows of CSV data imported.",
+	})
+
+	// ImportDuration observes the time taken by imports, from reading the
+	// CSV data to storing the dataset, by status.
+	ImportDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "import_duration_seconds",
+		Help:      "Time taken by CSV imports by status.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"status"})
+
+	// QueryDuration observes the time taken by row queries by the backend
+	// storing the dataset, duckdb or turso.
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "query_duration_seconds",
+		Help:      "Time taken by row queries by backend.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"backend"})
+
+	// PersistDuration observes the time taken to copy datasets to Turso, by
+	// status.
+	PersistDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "persist_duration_seconds",
+		Help:      "Time taken to persist datasets to Turso by status.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"status"})
+)
+
+// Collectors returns the collectors of the metrics above.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		Requests,
+		RequestDuration,
+		Imports,
+		ImportBytes,
+		ImportRows,
+		ImportDuration,
+		QueryDuration,
+		PersistDuration,
+	}
+}
+
+// Status returns the status label of an operation that returned err.
+func Status(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "ok"
+}
+
+// Since returns the seconds elapsed since start.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// DatabaseGauges returns gauges of the open DuckDB connections and the bytes
+// in the data directory, read from the given functions on each scrape.
+func DatabaseGauges(openConnections func() int, dataDirBytes func() int64) []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "duckdb_open_connections",
+			Help:      "DuckDB dataset files held open.",
+		}, func() float64 { return float64(openConnections()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "data_dir_bytes",
+			Help:      "Size of the DuckDB files in the data directory.",
+		}, func() float64 { return float64(dataDirBytes()) }),
+	}
+}
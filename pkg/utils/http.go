@@ -1,21 +1,41 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/JayJamieson/csv-api/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var HTTPClient = &http.Client{
 	Timeout: 30 * time.Second,
 }
 
-func DownloadFile(url string) (io.ReadCloser, error) {
-	resp, err := HTTPClient.Get(url)
+var tracer = tracing.Tracer("github.com/JayJamieson/csv-api/pkg/utils")
+
+func DownloadFile(ctx context.Context, url string) (body io.ReadCloser, err error) {
+	ctx, span := tracer.Start(ctx, "DownloadFile", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("url.full", url)))
+	defer func() { tracing.End(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()